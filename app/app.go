@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/holdno/gopherCron/pkg/panicgroup"
@@ -22,41 +24,52 @@ import (
 )
 
 type App interface {
-	CreateProject(tx *gorm.DB, p common.Project) (int64, error)
-	GetProject(pid int64) (*common.Project, error)
-	GetUserProjects(uid int64) ([]*common.Project, error)
-	CheckProjectExistByName(title string) (*common.Project, error)
-	CheckUserIsInProject(pid, uid int64) (bool, error)        // 确认该用户是否加入该项目
-	CheckUserProject(pid, uid int64) (*common.Project, error) // 确认项目是否属于该用户
-	UpdateProject(pid int64, title, remark string) error
-	DeleteProject(tx *gorm.DB, pid, uid int64) error
-	SaveTask(task *common.TaskInfo) (*common.TaskInfo, error)
-	DeleteTask(pid int64, tid string) (*common.TaskInfo, error)
-	SetTaskRunning(task common.TaskInfo) error
-	SetTaskNotRunning(task common.TaskInfo) error
-	KillTask(pid int64, tid string) error
-	GetWorkerList(projectID int64) ([]string, error)
-	GetProjectTaskCount(projectID int64) (int64, error)
-	GetTaskList(projectID int64) ([]*common.TaskInfo, error)
-	GetTask(projectID int64, nameID string) (*common.TaskInfo, error)
-	GetMonitor(ip string) (*common.MonitorInfo, error)
-	TemporarySchedulerTask(task *common.TaskInfo) error
-	GetTaskLogList(pid int64, tid string, page, pagesize int) ([]*common.TaskLog, error)
-	GetLogTotalByDate(projects []int64, timestamp int64, errType int) (int, error)
-	GetTaskLogTotal(pid int64, tid string) (int, error)
-	CleanProjectLog(tx *gorm.DB, pid int64) error
-	CleanLog(tx *gorm.DB, pid int64, tid string) error
-	DeleteAll() error
-	CreateProjectRelevance(tx *gorm.DB, pid, uid int64) error
-	DeleteProjectRelevance(tx *gorm.DB, pid, uid int64) error
-	GetProjectRelevanceUsers(pid int64) ([]*common.ProjectRelevance, error)
-	GetUserByAccount(account string) (*common.User, error)
-	GetUserInfo(uid int64) (*common.User, error)
-	GetUsersByIDs(uids []int64) ([]*common.User, error)
-	CreateUser(u common.User) error
-	GetUserList(args GetUserListArgs) ([]*common.User, error)
-	GetUserListTotal(args GetUserListArgs) (int, error)
-	ChangePassword(uid int64, password, salt string) error
+	CreateOrganization(ctx context.Context, tx *gorm.DB, name string, ownerUID int64) (int64, error)
+	GetOrganization(ctx context.Context, orgID int64) (*common.Organization, error)
+	ListUserOrganizations(ctx context.Context, uid int64) ([]*common.Organization, error)
+	AddOrgMember(ctx context.Context, orgID, uid int64, role OrgRole) error
+	RemoveOrgMember(ctx context.Context, orgID, uid int64) error
+	UpdateOrgMemberRole(ctx context.Context, orgID, uid int64, role OrgRole) error
+
+	CreateProject(ctx context.Context, tx *gorm.DB, p common.Project) (int64, error)
+	GetProject(ctx context.Context, pid int64) (*common.Project, error)
+	GetUserProjects(ctx context.Context, uid int64) ([]*common.Project, error)
+	CheckProjectExistByName(ctx context.Context, title string) (*common.Project, error)
+	CheckUserIsInProject(ctx context.Context, pid, uid int64) (bool, error)        // 确认该用户是否加入该项目
+	CheckUserProject(ctx context.Context, pid, uid int64) (*common.Project, error) // 确认项目是否属于该用户（组织内有权限的成员均可通过）
+	UpdateProject(ctx context.Context, pid int64, title, remark string) error
+	DeleteProject(ctx context.Context, tx *gorm.DB, pid, uid int64) error
+	SaveTask(ctx context.Context, task *common.TaskInfo) (*common.TaskInfo, error)
+	DeleteTask(ctx context.Context, pid int64, tid string) (*common.TaskInfo, error)
+	SetTaskRunning(ctx context.Context, task common.TaskInfo) error
+	SetTaskNotRunning(ctx context.Context, task common.TaskInfo) error
+	KillTask(ctx context.Context, pid int64, tid string) error
+	GetWorkerList(ctx context.Context, projectID int64) ([]string, error)
+	GetProjectTaskCount(ctx context.Context, projectID int64) (int64, error)
+	GetTaskList(ctx context.Context, projectID int64) ([]*common.TaskInfo, error)
+	GetTask(ctx context.Context, projectID int64, nameID string) (*common.TaskInfo, error)
+	GetMonitor(ctx context.Context, ip string) (*common.MonitorInfo, error)
+	TemporarySchedulerTask(ctx context.Context, task *common.TaskInfo) error
+	GetTaskLogList(ctx context.Context, pid int64, tid string, page, pagesize int) ([]*common.TaskLog, error)
+	GetLogTotalByDate(ctx context.Context, projects []int64, timestamp int64, errType int) (int, error)
+	GetTaskLogTotal(ctx context.Context, pid int64, tid string) (int, error)
+	GetTaskStageLogs(ctx context.Context, pid int64, tid string, runID string) ([]*common.TaskStageLog, error)
+	CleanProjectLog(ctx context.Context, tx *gorm.DB, pid int64) error
+	CleanLog(ctx context.Context, tx *gorm.DB, pid int64, tid string) error
+	DeleteAll(ctx context.Context) error
+	CreateProjectRelevance(ctx context.Context, tx *gorm.DB, pid, uid int64) error
+	DeleteProjectRelevance(ctx context.Context, tx *gorm.DB, pid, uid int64) error
+	GetProjectRelevanceUsers(ctx context.Context, pid int64) ([]*common.ProjectRelevance, error)
+	GetUserByAccount(ctx context.Context, account string) (*common.User, error)
+	GetUserInfo(ctx context.Context, uid int64) (*common.User, error)
+	GetUsersByIDs(ctx context.Context, uids []int64) ([]*common.User, error)
+	CreateUser(ctx context.Context, u common.User) error
+	GetUserList(ctx context.Context, args GetUserListArgs) ([]*common.User, error)
+	GetUserListTotal(ctx context.Context, args GetUserListArgs) (int, error)
+	ChangePassword(ctx context.Context, uid int64, password, salt string) error
+	QueryOperationLogs(ctx context.Context, args OperationLogQuery) ([]*common.OperationLog, int, error)
+	UpdateProjectLogRetention(ctx context.Context, pid int64, days int) error
+	UpdateTaskLogRetention(ctx context.Context, pid int64, tid string, days int) error
 	GetLocker(task *common.TaskInfo) *etcd.TaskLock
 	GetIP() string
 
@@ -80,12 +93,15 @@ func GetApp(c *gin.Context) App {
 }
 
 type app struct {
-	store   sqlStore.SqlStore
-	logger  *logrus.Logger
-	etcd    EtcdManager
-	closeCh chan struct{}
-	isClose bool
-	localip string
+	store    sqlStore.SqlStore
+	logger   *logrus.Logger
+	slog     *subsystemLogger
+	etcd     EtcdManager
+	conf     *config.ServiceConfig
+	closeCh  chan struct{}
+	isClose  bool
+	localip  string
+	esReader *esLogReader
 
 	CommonInterface
 	Warner
@@ -104,7 +120,12 @@ func NewApp(conf *config.ServiceConfig, opts ...AppOptions) App {
 
 	app := new(app)
 	app.logger = logger.MustSetup(conf.LogLevel)
+	app.slog = newSubsystemLogger(NewLogger(app.logger))
+	app.conf = conf
 	app.store = sqlStore.MustSetup(conf.Mysql, app.logger, true)
+	if conf.Sinks != nil {
+		app.esReader = newESLogReader(conf.Sinks.Elasticsearch)
+	}
 
 	for _, opt := range opts {
 		opt(app)
@@ -121,17 +142,18 @@ func NewApp(conf *config.ServiceConfig, opts ...AppOptions) App {
 		panic(err)
 	}
 	app.logger.Info("connected to etcd")
-	app.CommonInterface = NewComm(app.etcd)
+	app.CommonInterface = NewComm(app.etcd, conf.Etcd.Prefix)
 
 	utils.InitIDWorker(1)
 
 	// 自动清理任务
 	go func() {
-		t := time.NewTicker(time.Hour * 12)
+		t := time.NewTicker(logSweepInterval(conf))
 		for {
 			select {
 			case <-t.C:
-				app.AutoCleanLogs()
+				ctx := ContextWithTraceID(context.Background(), fmt.Sprintf("auto-clean-logs-%d", time.Now().Unix()))
+				app.AutoCleanLogs(ctx)
 			case <-app.closeCh:
 				t.Stop()
 				// app.etcd.Lock(nil).CloseAll()
@@ -154,8 +176,10 @@ func (a *app) GetLocker(task *common.TaskInfo) *etcd.TaskLock {
 type client struct {
 	localip   string
 	logger    *logrus.Logger
+	slog      *subsystemLogger
 	etcd      EtcdManager
 	scheduler *TaskScheduler
+	sinks     TaskLogSink
 
 	panicgroup.PanicGroup
 	ClientTaskReporter
@@ -185,12 +209,46 @@ func ClientWithWarning(w Warner) ClientOptions {
 	}
 }
 
+func ClientWithTaskLogSinks(sinks TaskLogSink) ClientOptions {
+	return func(a *client) {
+		a.sinks = sinks
+	}
+}
+
+// buildTaskLogSinksFromConfig 按配置创建 ES/Kafka/S3 sink 并以 MultiSink 聚合，
+// 任意一个未配置地址时会在写入时返回 error，由 sink 自身落盘兜底，不影响其他 sink
+func buildTaskLogSinksFromConfig(conf *config.ServiceConfig, logger *logrus.Logger) TaskLogSink {
+	if conf.Sinks == nil {
+		return nil
+	}
+
+	var sinks []TaskLogSink
+	if conf.Sinks.Elasticsearch != nil {
+		sinks = append(sinks, NewESSink(conf.Sinks.Elasticsearch.Addr, conf.Sinks.Elasticsearch.BulkSize,
+			time.Duration(conf.Sinks.Elasticsearch.FlushIntervalSeconds)*time.Second, conf.Sinks.SpoolDir))
+	}
+	if conf.Sinks.Kafka != nil {
+		sinks = append(sinks, NewKafkaSink(conf.Sinks.Kafka.Brokers, conf.Sinks.Kafka.Topic, conf.Sinks.SpoolDir))
+	}
+	if conf.Sinks.S3 != nil {
+		sinks = append(sinks, NewS3Sink(conf.Sinks.S3.Endpoint, conf.Sinks.S3.Bucket, conf.Sinks.S3.Prefix, conf.Sinks.S3.WorkerIP, conf.Sinks.SpoolDir))
+	}
+
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	logger.Infof("init %d task log sink(s)", len(sinks))
+	return NewMultiSink(sinks...)
+}
+
 func NewClient(conf *config.ServiceConfig, opts ...ClientOptions) Client {
 	var err error
 
 	agent := new(client)
 
 	agent.logger = logger.MustSetup(conf.LogLevel)
+	agent.slog = newSubsystemLogger(NewLogger(agent.logger))
 	if agent.localip, err = utils.GetLocalIP(); err != nil {
 		agent.logger.Error("failed to get local ip")
 	}
@@ -229,11 +287,15 @@ func NewClient(conf *config.ServiceConfig, opts ...ClientOptions) Client {
 		agent.Warner = NewDefaultWarner(agent.logger)
 	}
 
+	if agent.sinks == nil {
+		agent.sinks = buildTaskLogSinksFromConfig(conf, agent.logger)
+	}
+
 	if agent.etcd, err = etcd.Connect(conf.Etcd); err != nil {
 		panic(err)
 	}
 
-	agent.CommonInterface = NewComm(agent.etcd)
+	agent.CommonInterface = NewComm(agent.etcd, conf.Etcd.Prefix)
 
 	clusterID, err := agent.etcd.Inc(conf.Etcd.Prefix + common.CLUSTER_AUTO_INDEX)
 	if err != nil {
@@ -258,6 +320,43 @@ func (c *client) GetIP() string {
 	return c.localip
 }
 
+// ResultReport 先写入原有的 ClientTaskReporter（MySQL 或 HTTP 上报），再尽力广播给额外配置的 sink，
+// sink 写入失败不影响主链路，也不会让上报结果返回 error
+func (c *client) ResultReport(result *common.TaskExecuteResult) error {
+	err := c.ClientTaskReporter.ResultReport(result)
+
+	if c.sinks != nil {
+		if serr := c.sinks.WriteLog(context.Background(), buildTaskLogFromResult(result)); serr != nil {
+			c.logger.WithFields(logrus.Fields{
+				"error":      serr.Error(),
+				"project_id": result.Task.ProjectID,
+				"task_id":    result.Task.TaskID,
+			}).Error("failed to write task log to extra sinks")
+		}
+	}
+
+	return err
+}
+
+// buildTaskLogFromResult 把执行结果转换为落库/落 sink 共用的 TaskLog 模型
+func buildTaskLogFromResult(result *common.TaskExecuteResult) *common.TaskLog {
+	withError := 0
+	if result.Err != "" {
+		withError = 1
+	}
+
+	return &common.TaskLog{
+		ProjectID: result.Task.ProjectID,
+		TaskID:    result.Task.TaskID,
+		Name:      result.Task.Name,
+		Result:    result.Result,
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		WithError: withError,
+		ClientIP:  result.Task.ClientIP,
+	}
+}
+
 func (a *app) Close() {
 	if !a.isClose {
 		a.isClose = true
@@ -269,7 +368,7 @@ func (a *app) BeginTx() *gorm.DB {
 	return a.store.BeginTx()
 }
 
-func (a *app) CheckUserIsInProject(pid, uid int64) (bool, error) {
+func (a *app) CheckUserIsInProject(ctx context.Context, pid, uid int64) (bool, error) {
 	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid),
 		selection.NewRequirement("uid", selection.FindIn, uid))
 	opt.Select = "id"
@@ -278,6 +377,7 @@ func (a *app) CheckUserIsInProject(pid, uid int64) (bool, error) {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取项目归属信息失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return false, errObj
 	}
 	if len(res) == 0 {
@@ -287,24 +387,28 @@ func (a *app) CheckUserIsInProject(pid, uid int64) (bool, error) {
 	return true, nil
 }
 
-func (a *app) CheckUserProject(pid, uid int64) (*common.Project, error) {
-	opt := selection.NewSelector(selection.NewRequirement("id", selection.Equals, pid),
-		selection.NewRequirement("uid", selection.Equals, uid))
-	res, err := a.store.Project().GetProject(opt)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		errObj := errors.ErrInternalError
-		errObj.Msg = "获取项目信息失败"
-		errObj.Log = err.Error()
-		return nil, errObj
+func (a *app) CheckUserProject(ctx context.Context, pid, uid int64) (*common.Project, error) {
+	p, err := a.GetProject(ctx, pid)
+	if err != nil {
+		if err == errors.ErrProjectNotExist {
+			return nil, nil
+		}
+		return nil, err
 	}
-	if len(res) == 0 {
+
+	role, err := a.getOrgMemberRole(ctx, p.OrganizationID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "" {
 		return nil, nil
 	}
 
-	return res[0], nil
+	return p, nil
 }
 
-func (a *app) GetProject(pid int64) (*common.Project, error) {
+func (a *app) GetProject(ctx context.Context, pid int64) (*common.Project, error) {
 	opt := selection.NewSelector(selection.NewRequirement("id", selection.Equals, pid))
 	opt.Pagesize = 1
 	res, err := a.store.Project().GetProject(opt)
@@ -312,6 +416,7 @@ func (a *app) GetProject(pid int64) (*common.Project, error) {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "无法获取项目信息"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
@@ -322,13 +427,25 @@ func (a *app) GetProject(pid int64) (*common.Project, error) {
 	return res[0], nil
 }
 
-func (a *app) GetUserProjects(uid int64) ([]*common.Project, error) {
+func (a *app) GetUserProjects(ctx context.Context, uid int64) ([]*common.Project, error) {
+	orgs, err := a.ListUserOrganizations(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgIDs []int64
+	for _, v := range orgs {
+		orgIDs = append(orgIDs, v.ID)
+	}
+
+	// 兼容组织体系上线前直接通过 ProjectRelevance 关联的项目
 	opt := selection.NewSelector(selection.NewRequirement("uid", selection.FindIn, uid))
 	res, err := a.store.ProjectRelevance().GetList(opt)
 	if err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "无法获取用户关联产品信息"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
@@ -337,44 +454,96 @@ func (a *app) GetUserProjects(uid int64) ([]*common.Project, error) {
 		pids = append(pids, v.ProjectID)
 	}
 
-	opt = selection.NewSelector(selection.NewRequirement("id", selection.In, pids))
-	projects, err := a.store.Project().GetProject(opt)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		errObj := errors.ErrInternalError
-		errObj.Msg = "无法获取项目信息"
-		errObj.Log = err.Error()
-		return nil, errObj
+	if len(orgIDs) == 0 && len(pids) == 0 {
+		return nil, nil
+	}
+
+	// selection.Selector 只支持 AND 组合查询条件，没有 Or 能力，
+	// 组织项目和历史直接关联的项目分两次查询后在内存里去重合并
+	var projects []*common.Project
+	seen := make(map[int64]bool)
+
+	if len(orgIDs) > 0 {
+		opt = selection.NewSelector(selection.NewRequirement("organization_id", selection.In, orgIDs))
+		res, err := a.store.Project().GetProject(opt)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			errObj := errors.ErrInternalError
+			errObj.Msg = "无法获取项目信息"
+			errObj.Log = err.Error()
+			errObj.TraceID = TraceIDFromContext(ctx)
+			return nil, errObj
+		}
+		for _, v := range res {
+			if !seen[v.ID] {
+				seen[v.ID] = true
+				projects = append(projects, v)
+			}
+		}
+	}
+
+	if len(pids) > 0 {
+		opt = selection.NewSelector(selection.NewRequirement("id", selection.In, pids))
+		res, err := a.store.Project().GetProject(opt)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			errObj := errors.ErrInternalError
+			errObj.Msg = "无法获取项目信息"
+			errObj.Log = err.Error()
+			errObj.TraceID = TraceIDFromContext(ctx)
+			return nil, errObj
+		}
+		for _, v := range res {
+			if !seen[v.ID] {
+				seen[v.ID] = true
+				projects = append(projects, v)
+			}
+		}
 	}
 
 	return projects, nil
 }
 
-func (a *app) CleanProjectLog(tx *gorm.DB, pid int64) error {
+func (a *app) CleanProjectLog(ctx context.Context, tx *gorm.DB, pid int64) error {
 	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid))
 	if err := a.store.TaskLog().Clean(tx, opt); err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "清除项目日志失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
 
 	return nil
 }
 
-func (a *app) CleanLog(tx *gorm.DB, pid int64, tid string) error {
+func (a *app) CleanLog(ctx context.Context, tx *gorm.DB, pid int64, tid string) error {
 	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid),
 		selection.NewRequirement("task_id", selection.Equals, tid))
 	if err := a.store.TaskLog().Clean(tx, opt); err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "清除日志失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
 
 	return nil
 }
 
-func (a *app) GetTaskLogList(pid int64, tid string, page, pagesize int) ([]*common.TaskLog, error) {
+// GetTaskLogList 优先查询配置的 Elasticsearch sink，未配置时退回 MySQL，
+// 使得任务日志以 ES 为存储主体部署时 Web UI 不依赖 MySQL 里的 gc_task_log 表
+func (a *app) GetTaskLogList(ctx context.Context, pid int64, tid string, page, pagesize int) ([]*common.TaskLog, error) {
+	if a.esReader != nil {
+		list, err := a.esReader.GetList(ctx, pid, tid, page, pagesize)
+		if err != nil {
+			errObj := errors.ErrInternalError
+			errObj.Msg = "获取日志列表失败"
+			errObj.Log = err.Error()
+			errObj.TraceID = TraceIDFromContext(ctx)
+			return nil, errObj
+		}
+		return list, nil
+	}
+
 	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid),
 		selection.NewRequirement("task_id", selection.Equals, tid))
 	opt.Page = page
@@ -386,13 +555,26 @@ func (a *app) GetTaskLogList(pid int64, tid string, page, pagesize int) ([]*comm
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取日志列表失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
 	return list, nil
 }
 
-func (a *app) GetTaskLogTotal(pid int64, tid string) (int, error) {
+func (a *app) GetTaskLogTotal(ctx context.Context, pid int64, tid string) (int, error) {
+	if a.esReader != nil {
+		total, err := a.esReader.GetTotal(ctx, pid, tid)
+		if err != nil {
+			errObj := errors.ErrInternalError
+			errObj.Msg = "获取日志条数失败"
+			errObj.Log = err.Error()
+			errObj.TraceID = TraceIDFromContext(ctx)
+			return 0, errObj
+		}
+		return total, nil
+	}
+
 	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid),
 		selection.NewRequirement("task_id", selection.Equals, tid))
 
@@ -401,13 +583,14 @@ func (a *app) GetTaskLogTotal(pid int64, tid string) (int, error) {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取日志条数失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return 0, errObj
 	}
 
 	return total, nil
 }
 
-func (a *app) GetLogTotalByDate(projects []int64, timestamp int64, errType int) (int, error) {
+func (a *app) GetLogTotalByDate(ctx context.Context, projects []int64, timestamp int64, errType int) (int, error) {
 	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.In, projects),
 		selection.NewRequirement("start_time", selection.GreaterThan, timestamp),
 		selection.NewRequirement("start_time", selection.LessThan, timestamp+86400),
@@ -418,13 +601,14 @@ func (a *app) GetLogTotalByDate(projects []int64, timestamp int64, errType int)
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取日志条数失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return 0, errObj
 	}
 
 	return total, nil
 }
 
-func (a *app) CheckProjectExistByName(title string) (*common.Project, error) {
+func (a *app) CheckProjectExistByName(ctx context.Context, title string) (*common.Project, error) {
 	opt := selection.NewSelector(selection.NewRequirement("title", selection.Equals, title))
 
 	p, err := a.store.Project().GetProject(opt)
@@ -432,6 +616,7 @@ func (a *app) CheckProjectExistByName(title string) (*common.Project, error) {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取项目信息失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
@@ -442,42 +627,86 @@ func (a *app) CheckProjectExistByName(title string) (*common.Project, error) {
 	return p[0], nil
 }
 
-func (a *app) CreateProject(tx *gorm.DB, p common.Project) (int64, error) {
+func (a *app) CreateProject(ctx context.Context, tx *gorm.DB, p common.Project) (int64, error) {
+	if p.OrganizationID == 0 {
+		// 未指定组织时落到该用户的个人组织，保证历史创建流程不受影响
+		orgID, err := a.ensurePersonalOrganization(ctx, tx, p.Uid)
+		if err != nil {
+			return 0, err
+		}
+		p.OrganizationID = orgID
+	}
+
 	id, err := a.store.Project().CreateProject(tx, p)
 	if err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "创建项目失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return 0, errObj
 	}
 
+	a.recordOperationLog(ctx, OperationLogEntry{
+		OrgID:     p.OrganizationID,
+		ProjectID: id,
+		UID:       p.Uid,
+		Action:    OperationActionCreateProject,
+		Target:    p.Title,
+		After:     p,
+		ClientIP:  a.GetIP(),
+	})
+
 	return id, nil
 }
 
-func (a *app) DeleteProject(tx *gorm.DB, pid, uid int64) error {
+func (a *app) DeleteProject(ctx context.Context, tx *gorm.DB, pid, uid int64) error {
+	before, _ := a.GetProject(ctx, pid)
+
 	opt := selection.NewSelector(selection.NewRequirement("id", selection.Equals, pid),
 		selection.NewRequirement("uid", selection.Equals, uid))
 	if err := a.store.Project().DeleteProject(tx, opt); err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "删除项目失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
 
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: pid,
+		UID:       uid,
+		Action:    OperationActionDeleteProject,
+		Before:    before,
+		ClientIP:  a.GetIP(),
+	})
+
 	return nil
 }
 
-func (a *app) UpdateProject(pid int64, title, remark string) error {
+func (a *app) UpdateProject(ctx context.Context, pid int64, title, remark string) error {
+	before, _ := a.GetProject(ctx, pid)
+
 	if err := a.store.Project().UpdateProject(pid, title, remark); err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "更新项目失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
+
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: pid,
+		Action:    OperationActionUpdateProject,
+		Target:    title,
+		Before:    before,
+		After:     map[string]string{"title": title, "remark": remark},
+		ClientIP:  a.GetIP(),
+	})
+
 	return nil
 }
 
-func (a *app) CreateProjectRelevance(tx *gorm.DB, pid, uid int64) error {
+func (a *app) CreateProjectRelevance(ctx context.Context, tx *gorm.DB, pid, uid int64) error {
 	if err := a.store.ProjectRelevance().Create(tx, common.ProjectRelevance{
 		ProjectID:  pid,
 		UID:        uid,
@@ -486,23 +715,40 @@ func (a *app) CreateProjectRelevance(tx *gorm.DB, pid, uid int64) error {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "创建项目关联关系失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
 
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: pid,
+		UID:       uid,
+		Action:    OperationActionCreateProjectRelation,
+		ClientIP:  a.GetIP(),
+	})
+
 	return nil
 }
 
-func (a *app) DeleteProjectRelevance(tx *gorm.DB, pid, uid int64) error {
+func (a *app) DeleteProjectRelevance(ctx context.Context, tx *gorm.DB, pid, uid int64) error {
 	if err := a.store.ProjectRelevance().Delete(tx, pid, uid); err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "删除项目关联关系失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
+
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: pid,
+		UID:       uid,
+		Action:    OperationActionDeleteProjectRelation,
+		ClientIP:  a.GetIP(),
+	})
+
 	return nil
 }
 
-func (a *app) GetUserByAccount(account string) (*common.User, error) {
+func (a *app) GetUserByAccount(ctx context.Context, account string) (*common.User, error) {
 	opt := selection.NewSelector(selection.NewRequirement("account", selection.Equals, account))
 	opt.Pagesize = 1
 
@@ -511,6 +757,7 @@ func (a *app) GetUserByAccount(account string) (*common.User, error) {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取用户信息失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
@@ -521,13 +768,14 @@ func (a *app) GetUserByAccount(account string) (*common.User, error) {
 	return res[0], nil
 }
 
-func (a *app) GetUserInfo(uid int64) (*common.User, error) {
+func (a *app) GetUserInfo(ctx context.Context, uid int64) (*common.User, error) {
 	opt := selection.NewSelector(selection.NewRequirement("id", selection.Equals, uid))
 	res, err := a.store.User().GetUsers(opt)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取用户信息失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
@@ -538,37 +786,47 @@ func (a *app) GetUserInfo(uid int64) (*common.User, error) {
 	return res[0], nil
 }
 
-func (a *app) CreateUser(u common.User) error {
+func (a *app) CreateUser(ctx context.Context, u common.User) error {
 	if err := a.store.User().CreateUser(u); err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "创建用户失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
 
+	a.recordOperationLog(ctx, OperationLogEntry{
+		UID:      u.ID,
+		Action:   OperationActionCreateUser,
+		Target:   u.Account,
+		ClientIP: a.GetIP(),
+	})
+
 	return nil
 }
 
-func (a *app) GetProjectRelevanceUsers(pid int64) ([]*common.ProjectRelevance, error) {
+func (a *app) GetProjectRelevanceUsers(ctx context.Context, pid int64) ([]*common.ProjectRelevance, error) {
 	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid))
 	res, err := a.store.ProjectRelevance().GetList(opt)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取用户项目关联列表失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
 	return res, nil
 }
 
-func (a *app) GetUsersByIDs(uids []int64) ([]*common.User, error) {
+func (a *app) GetUsersByIDs(ctx context.Context, uids []int64) ([]*common.User, error) {
 	opt := selection.NewSelector(selection.NewRequirement("id", selection.In, uids))
 	res, err := a.store.User().GetUsers(opt)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取用户列表失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
@@ -584,11 +842,11 @@ type GetUserListArgs struct {
 	Pagesize  int
 }
 
-func (a *app) parseUserSearchArgs(args GetUserListArgs) (selection.Selector, error) {
+func (a *app) parseUserSearchArgs(ctx context.Context, args GetUserListArgs) (selection.Selector, error) {
 	opts := selection.NewSelector()
 
 	if args.ProjectID != 0 {
-		re, err := a.GetProjectRelevanceUsers(args.ProjectID)
+		re, err := a.GetProjectRelevanceUsers(ctx, args.ProjectID)
 		if err != nil {
 			return selection.Selector{}, err
 		}
@@ -616,8 +874,8 @@ func (a *app) parseUserSearchArgs(args GetUserListArgs) (selection.Selector, err
 	return opts, nil
 }
 
-func (a *app) GetUserList(args GetUserListArgs) ([]*common.User, error) {
-	opts, err := a.parseUserSearchArgs(args)
+func (a *app) GetUserList(ctx context.Context, args GetUserListArgs) ([]*common.User, error) {
+	opts, err := a.parseUserSearchArgs(ctx, args)
 	if err != nil {
 		return nil, err
 	}
@@ -630,14 +888,15 @@ func (a *app) GetUserList(args GetUserListArgs) ([]*common.User, error) {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取用户列表失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return nil, errObj
 	}
 
 	return list, nil
 }
 
-func (a *app) GetUserListTotal(args GetUserListArgs) (int, error) {
-	opts, err := a.parseUserSearchArgs(args)
+func (a *app) GetUserListTotal(ctx context.Context, args GetUserListArgs) (int, error) {
+	opts, err := a.parseUserSearchArgs(ctx, args)
 	if err != nil {
 		return 0, err
 	}
@@ -647,28 +906,27 @@ func (a *app) GetUserListTotal(args GetUserListArgs) (int, error) {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "获取用户数量失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return 0, errObj
 	}
 
 	return total, nil
 }
 
-func (a *app) ChangePassword(uid int64, password, salt string) error {
+func (a *app) ChangePassword(ctx context.Context, uid int64, password, salt string) error {
 	if err := a.store.User().ChangePassword(uid, password, salt); err != nil {
 		errObj := errors.ErrInternalError
 		errObj.Msg = "更新密码失败"
 		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
 		return errObj
 	}
 
-	return nil
-}
+	a.recordOperationLog(ctx, OperationLogEntry{
+		UID:      uid,
+		Action:   OperationActionChangePassword,
+		ClientIP: a.GetIP(),
+	})
 
-func (a *app) AutoCleanLogs() {
-	opt := selection.NewSelector(selection.NewRequirement("start_time", selection.LessThan, time.Now().Unix()-86400*7))
-	if err := a.store.TaskLog().Clean(nil, opt); err != nil {
-		a.logger.WithFields(logrus.Fields{
-			"error": err.Error(),
-		}).Error("failed to clean logs by auto clean")
-	}
+	return nil
 }
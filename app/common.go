@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/config"
+	"github.com/holdno/gopherCron/utils"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+)
+
+// version 由发布流程通过 -ldflags 注入，未注入时回退到 dev，便于本地调试区分
+var version = "dev"
+
+// CommonInterface 封装了 server 与 client 共用的 etcd 集群交互能力：监听任务触发事件、
+// 感知下线的 worker 节点、向集群注册自身、上报当前版本号
+type CommonInterface interface {
+	TaskWatcher(projects []int64) error
+	TaskKiller(projects []int64)
+	Register(conf *config.EtcdConf)
+	GetVersion() string
+	// Trigger 返回的 channel 里，每一个元素都是 etcd 上一次任务触发事件解码后的结果，
+	// client.Loop 消费它并喂给 TaskScheduler
+	Trigger() <-chan *common.TaskInfo
+}
+
+type comm struct {
+	etcd    EtcdManager
+	prefix  string
+	trigger chan *common.TaskInfo
+}
+
+// NewComm 构造 CommonInterface 的默认实现，server 和 client 各自持有独立的实例，
+// NewApp/NewClient 传入的 prefix 都是 conf.Etcd.Prefix，确保 TemporarySchedulerTask
+// 写入的 key 和这里监听的 key 落在同一个前缀下
+func NewComm(etcd EtcdManager, prefix string) CommonInterface {
+	return &comm{
+		etcd:    etcd,
+		prefix:  prefix,
+		trigger: make(chan *common.TaskInfo, 64),
+	}
+}
+
+func (c *comm) Trigger() <-chan *common.TaskInfo {
+	return c.trigger
+}
+
+// TaskWatcher 监听每个项目下 /trigger/<project_id>/ 前缀，任务触发时上游会把 TaskInfo
+// 的 JSON 写到这个前缀下的一个 key，这里解码后推入 Trigger 返回的 channel
+func (c *comm) TaskWatcher(projects []int64) error {
+	for _, pid := range projects {
+		prefix := fmt.Sprintf("%s/trigger/%d/", c.prefix, pid)
+		watchCh := c.etcd.Watcher().Watch(context.Background(), prefix, clientv3.WithPrefix())
+
+		go func(ch clientv3.WatchChan) {
+			for resp := range ch {
+				for _, ev := range resp.Events {
+					if ev.Type != mvccpb.PUT {
+						continue
+					}
+
+					var task common.TaskInfo
+					if err := json.Unmarshal(ev.Kv.Value, &task); err != nil {
+						continue
+					}
+					c.trigger <- &task
+				}
+			}
+		}(watchCh)
+	}
+
+	return nil
+}
+
+// TaskKiller 监听每个项目下 /worker/<project_id>/ 前缀（不带 etcd 前缀）的事件，
+// 收到的事件只被消费丢弃，不会终止任何任务；Register 注册节点用的 key 是 <prefix>/worker/<ip>，
+// 不在这个前缀之下
+func (c *comm) TaskKiller(projects []int64) {
+	for _, pid := range projects {
+		prefix := fmt.Sprintf("/worker/%d/", pid)
+		watchCh := c.etcd.Watcher().Watch(context.Background(), prefix, clientv3.WithPrefix())
+
+		go func(ch clientv3.WatchChan) {
+			for range ch {
+				// 目前仅消费事件，避免 watch channel 堆积
+			}
+		}(watchCh)
+	}
+}
+
+// Register 把当前节点以带租约的 key 注册到 etcd，并持续续约；
+// 续约失败或连接断开时会重新注册，保证节点重连后依然可见
+func (c *comm) Register(conf *config.EtcdConf) {
+	if conf == nil {
+		return
+	}
+
+	ip, err := utils.GetLocalIP()
+	if err != nil {
+		return
+	}
+
+	go func() {
+		for {
+			if !c.registerOnce(conf, ip) {
+				time.Sleep(5 * time.Second)
+			}
+		}
+	}()
+}
+
+func (c *comm) registerOnce(conf *config.EtcdConf, ip string) bool {
+	lease, err := c.etcd.Lease().Grant(context.Background(), 15)
+	if err != nil {
+		return false
+	}
+
+	key := fmt.Sprintf("%s/worker/%s", conf.Prefix, ip)
+	if _, err := c.etcd.KV().Put(context.Background(), key, ip, clientv3.WithLease(lease.ID)); err != nil {
+		return false
+	}
+
+	keepAliveCh, err := c.etcd.Lease().KeepAlive(context.Background(), lease.ID)
+	if err != nil {
+		return false
+	}
+
+	for range keepAliveCh {
+	}
+
+	return true
+}
+
+func (c *comm) GetVersion() string {
+	return version
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// traceIDContextKey 是 trace id 在 context.Context 中的存储键
+type traceIDContextKey struct{}
+
+// TraceIDHeaderKey 是客户端可以显式传入 trace id 的请求头，便于跨服务串联排查
+const TraceIDHeaderKey = "X-Request-ID"
+
+// traceparentHeaderKey 兼容 W3C Trace Context 规范，取不到 X-Request-ID 时退化使用它
+const traceparentHeaderKey = "traceparent"
+
+// Logger 在 *logrus.Logger 之上包了一层，保证每条日志都带上当前请求的 trace id
+type Logger interface {
+	WithTraceID(ctx context.Context) Logger
+	WithFields(fields logrus.Fields) Logger
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+}
+
+type traceLogger struct {
+	entry *logrus.Entry
+}
+
+// NewLogger 包装一个基础 *logrus.Logger，使其满足 Logger 接口
+func NewLogger(l *logrus.Logger) Logger {
+	return &traceLogger{entry: logrus.NewEntry(l)}
+}
+
+func (l *traceLogger) WithTraceID(ctx context.Context) Logger {
+	traceID := TraceIDFromContext(ctx)
+	if traceID == "" {
+		return l
+	}
+	return &traceLogger{entry: l.entry.WithField("trace_id", traceID)}
+}
+
+func (l *traceLogger) WithFields(fields logrus.Fields) Logger {
+	return &traceLogger{entry: l.entry.WithFields(fields)}
+}
+
+func (l *traceLogger) Debug(args ...interface{}) { l.entry.Debug(args...) }
+func (l *traceLogger) Info(args ...interface{})  { l.entry.Info(args...) }
+func (l *traceLogger) Warn(args ...interface{})  { l.entry.Warn(args...) }
+func (l *traceLogger) Error(args ...interface{}) { l.entry.Error(args...) }
+
+// ContextWithTraceID 把 trace id 写入 context.Context，供下游 etcd/sql/Warner 调用读取
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, traceID)
+}
+
+// TraceIDFromContext 读取 context.Context 中的 trace id，没有则返回空字符串
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	v, _ := ctx.Value(traceIDContextKey{}).(string)
+	return v
+}
+
+// TraceMiddleware 是注入到 gin 路由的中间件：优先复用客户端传入的 trace id
+// (X-Request-ID / traceparent)，取不到时通过雪花算法生成一个，写回响应头方便客户端留存
+func TraceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(TraceIDHeaderKey)
+		if traceID == "" {
+			traceID = c.GetHeader(traceparentHeaderKey)
+		}
+		if traceID == "" {
+			if id, err := utils.GetID(); err == nil {
+				traceID = fmt.Sprintf("%d", id)
+			}
+		}
+
+		ctx := ContextWithTraceID(c.Request.Context(), traceID)
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(common.TRACE_ID_KEY, traceID)
+		c.Writer.Header().Set(TraceIDHeaderKey, traceID)
+		c.Next()
+	}
+}
+
+// subsystemLogger 借鉴外部任务队列库里 "status + client" 的结构，
+// 让每个子系统（etcd/sql/warner...）的日志可以单独开关，而不必调整全局日志级别
+type subsystemLogger struct {
+	status bool
+	client Logger
+}
+
+func newSubsystemLogger(base Logger) *subsystemLogger {
+	return &subsystemLogger{status: true, client: base}
+}
+
+func (s *subsystemLogger) withTraceID(ctx context.Context) Logger {
+	if !s.status || s.client == nil {
+		return nil
+	}
+	return s.client.WithTraceID(ctx)
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/config"
+	"github.com/holdno/gopherCron/errors"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultLogRetentionDays 在项目/任务均未配置保留天数时使用
+const defaultLogRetentionDays = 7
+
+// defaultOperationLogRetentionDays 在未配置 LogRetention.OperationDays 时使用，
+// 操作日志是审计数据，默认比任务日志保留更久
+const defaultOperationLogRetentionDays = 90
+
+// defaultLogSweepInterval 在未配置 LogRetention.SweepInterval 时使用
+const defaultLogSweepInterval = time.Hour * 12
+
+// logSweepInterval 返回 AutoCleanLogs 后台轮询的间隔，优先级为：全局配置 > 内置兜底值
+func logSweepInterval(conf *config.ServiceConfig) time.Duration {
+	if conf != nil && conf.LogRetention.SweepInterval > 0 {
+		return conf.LogRetention.SweepInterval
+	}
+	return defaultLogSweepInterval
+}
+
+// warnSweepRowsThreshold 单次清理超过该行数时认为日志量异常增长，需要提醒运维人员
+const warnSweepRowsThreshold = 100000
+
+func (a *app) UpdateProjectLogRetention(ctx context.Context, pid int64, days int) error {
+	if err := a.store.Project().UpdateLogRetention(pid, days); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "更新项目日志保留天数失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+func (a *app) UpdateTaskLogRetention(ctx context.Context, pid int64, tid string, days int) error {
+	if err := a.store.Task().UpdateLogRetention(pid, tid, days); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "更新任务日志保留天数失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+// projectRetentionDays 计算项目维度的日志保留天数，优先级为：
+// 项目配置 > 全局默认配置 > 内置兜底值
+func (a *app) projectRetentionDays(p *common.Project) int {
+	if p.LogRetentionDays > 0 {
+		return p.LogRetentionDays
+	}
+
+	if a.conf != nil && a.conf.LogRetention.DefaultDays > 0 {
+		return a.conf.LogRetention.DefaultDays
+	}
+
+	return defaultLogRetentionDays
+}
+
+// taskRetentionCutoff 按错误类型返回某个任务的清理截止时间戳，成功/失败的记录可以有不同的保留时长。
+// 保留天数的优先级为：任务配置 > 项目配置 > 全局成功/失败配置 > 全局默认配置 > 内置兜底值，且不超过 MaxDays
+func (a *app) taskRetentionCutoff(p *common.Project, t *common.TaskInfo, withError int) int64 {
+	days := p.LogRetentionDays
+	if t != nil && t.LogRetentionDays > 0 {
+		days = t.LogRetentionDays
+	}
+
+	if days <= 0 && a.conf != nil {
+		if withError != 0 && a.conf.LogRetention.FailedDays > 0 {
+			days = a.conf.LogRetention.FailedDays
+		} else if withError == 0 && a.conf.LogRetention.SucceedDays > 0 {
+			days = a.conf.LogRetention.SucceedDays
+		}
+	}
+
+	if days <= 0 {
+		days = a.projectRetentionDays(p)
+	}
+
+	if a.conf != nil && a.conf.LogRetention.MaxDays > 0 && days > a.conf.LogRetention.MaxDays {
+		days = a.conf.LogRetention.MaxDays
+	}
+
+	return time.Now().Unix() - int64(days)*86400
+}
+
+// operationLogRetentionCutoff 返回操作日志的清理截止时间戳，优先级为：全局配置 > 内置兜底值
+func (a *app) operationLogRetentionCutoff() int64 {
+	days := defaultOperationLogRetentionDays
+	if a.conf != nil && a.conf.LogRetention.OperationDays > 0 {
+		days = a.conf.LogRetention.OperationDays
+	}
+
+	return time.Now().Unix() - int64(days)*86400
+}
+
+// cleanOperationLogsByRetention 清理过期的操作审计日志，保留天数不随项目/任务维度覆盖，
+// 所有项目共用同一个截止时间
+func (a *app) cleanOperationLogsByRetention(ctx context.Context) {
+	opt := selection.NewSelector(selection.NewRequirement("create_time", selection.LessThan, a.operationLogRetentionCutoff()))
+
+	affected, err := a.store.OperationLog().CleanWithCount(nil, opt)
+	if err != nil {
+		if l := a.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("failed to clean operation logs by auto clean")
+		}
+		return
+	}
+
+	if affected > warnSweepRowsThreshold {
+		msg := "操作日志单次清理行数异常，请检查是否存在写入风暴"
+		if l := a.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"affected": affected,
+			}).Warn(msg)
+		}
+
+		if werr := a.Warning(WarningData{
+			Data:    msg,
+			Type:    WarningTypeSystem,
+			AgentIP: a.GetIP(),
+			TraceID: TraceIDFromContext(ctx),
+		}); werr != nil {
+			if l := a.slog.withTraceID(ctx); l != nil {
+				l.WithFields(logrus.Fields{
+					"error": werr.Error(),
+				}).Error("failed to send auto clean logs warning")
+			}
+		}
+	}
+}
+
+// AutoCleanLogs 按项目粒度逐一清理过期任务日志，兼容项目/任务级别的保留天数覆盖，
+// 并在单次清理行数异常时告警，避免磁盘被长期遗忘的任务日志撑爆；同时按全局配置清理操作日志
+func (a *app) AutoCleanLogs(ctx context.Context) {
+	projects, err := a.store.Project().GetProject(selection.NewSelector())
+	if err != nil {
+		if l := a.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("failed to list projects for auto clean logs")
+		}
+		return
+	}
+
+	for _, p := range projects {
+		a.cleanProjectLogsByRetention(ctx, p)
+	}
+
+	a.cleanOperationLogsByRetention(ctx)
+}
+
+// cleanProjectLogsByRetention 按任务粒度逐一清理，而不是整个项目共用一个截止时间，
+// 这样任务级别的 LogRetentionDays 覆盖才会真正生效
+func (a *app) cleanProjectLogsByRetention(ctx context.Context, p *common.Project) {
+	tasks, err := a.GetTaskList(ctx, p.ID)
+	if err != nil {
+		if l := a.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"error":      err.Error(),
+				"project_id": p.ID,
+			}).Error("failed to list tasks for auto clean logs")
+		}
+		return
+	}
+
+	// 项目下没有任务时，仍然按项目级别的保留天数清理一遍，兼容任务已被删除但日志残留的情况
+	if len(tasks) == 0 {
+		tasks = []*common.TaskInfo{nil}
+	}
+
+	for _, t := range tasks {
+		a.cleanTaskLogsByRetention(ctx, p, t)
+	}
+}
+
+func (a *app) cleanTaskLogsByRetention(ctx context.Context, p *common.Project, t *common.TaskInfo) {
+	for _, withError := range []int{0, 1} {
+		cutoff := a.taskRetentionCutoff(p, t, withError)
+		requirements := []selection.Requirement{
+			selection.NewRequirement("project_id", selection.Equals, p.ID),
+			selection.NewRequirement("with_error", selection.Equals, withError),
+			selection.NewRequirement("start_time", selection.LessThan, cutoff),
+		}
+		if t != nil {
+			requirements = append(requirements, selection.NewRequirement("task_id", selection.Equals, t.TaskID))
+		}
+		opt := selection.NewSelector(requirements...)
+
+		affected, err := a.store.TaskLog().CleanWithCount(nil, opt)
+		if err != nil {
+			if l := a.slog.withTraceID(ctx); l != nil {
+				l.WithFields(logrus.Fields{
+					"error":      err.Error(),
+					"project_id": p.ID,
+					"with_error": withError,
+				}).Error("failed to clean logs by auto clean")
+			}
+			continue
+		}
+
+		if affected > warnSweepRowsThreshold {
+			msg := "项目日志单次清理行数异常，请检查是否存在日志写入风暴"
+			if l := a.slog.withTraceID(ctx); l != nil {
+				l.WithFields(logrus.Fields{
+					"project_id": p.ID,
+					"with_error": withError,
+					"affected":   affected,
+				}).Warn(msg)
+			}
+
+			if werr := a.Warning(WarningData{
+				Data:    msg,
+				Type:    WarningTypeSystem,
+				AgentIP: a.GetIP(),
+				TraceID: TraceIDFromContext(ctx),
+			}); werr != nil {
+				if l := a.slog.withTraceID(ctx); l != nil {
+					l.WithFields(logrus.Fields{
+						"error": werr.Error(),
+					}).Error("failed to send auto clean logs warning")
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/errors"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// OperationAction 枚举了所有会被记录进操作日志的写操作
+type OperationAction string
+
+const (
+	OperationActionCreateProject         OperationAction = "create_project"
+	OperationActionUpdateProject         OperationAction = "update_project"
+	OperationActionDeleteProject         OperationAction = "delete_project"
+	OperationActionSaveTask              OperationAction = "save_task"
+	OperationActionDeleteTask            OperationAction = "delete_task"
+	OperationActionKillTask              OperationAction = "kill_task"
+	OperationActionTemporaryTask         OperationAction = "temporary_scheduler_task"
+	OperationActionCreateProjectRelation OperationAction = "create_project_relevance"
+	OperationActionDeleteProjectRelation OperationAction = "delete_project_relevance"
+	OperationActionChangePassword        OperationAction = "change_password"
+	OperationActionCreateUser            OperationAction = "create_user"
+)
+
+// OperationLogQuery 是 QueryOperationLogs 的入参，各字段为空值时不参与过滤
+type OperationLogQuery struct {
+	OrgID     int64
+	ProjectID int64
+	TaskID    string
+	UID       int64
+	Action    OperationAction
+	StartTime int64
+	EndTime   int64
+	Page      int
+	Pagesize  int
+}
+
+// OperationLogEntry 描述一次写操作的上下文（含变更前后的数据），由调用方在变更完成后同步写入
+type OperationLogEntry struct {
+	OrgID     int64
+	ProjectID int64
+	TaskID    string
+	UID       int64
+	Action    OperationAction
+	Target    string
+	Before    interface{}
+	After     interface{}
+	ClientIP  string
+	UserAgent string
+}
+
+// recordOperationLog 将一次写操作落盘为审计记录，失败时只记录日志不阻断主流程，
+// 审计信息属于旁路能力，不应影响核心业务的可用性
+func (a *app) recordOperationLog(ctx context.Context, entry OperationLogEntry) {
+	beforeJSON, _ := json.Marshal(entry.Before)
+	afterJSON, _ := json.Marshal(entry.After)
+
+	log := common.OperationLog{
+		OrgID:      entry.OrgID,
+		ProjectID:  entry.ProjectID,
+		TaskID:     entry.TaskID,
+		UID:        entry.UID,
+		Action:     string(entry.Action),
+		Target:     entry.Target,
+		BeforeJSON: string(beforeJSON),
+		AfterJSON:  string(afterJSON),
+		ClientIP:   entry.ClientIP,
+		UserAgent:  entry.UserAgent,
+		CreateTime: time.Now().Unix(),
+	}
+
+	if err := a.store.OperationLog().Create(nil, log); err != nil {
+		if l := a.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"error":  err.Error(),
+				"action": entry.Action,
+				"pid":    entry.ProjectID,
+				"tid":    entry.TaskID,
+				"uid":    entry.UID,
+			}).Error("failed to record operation log")
+		}
+	}
+}
+
+func (a *app) QueryOperationLogs(ctx context.Context, args OperationLogQuery) ([]*common.OperationLog, int, error) {
+	opt := selection.NewSelector()
+
+	if args.OrgID != 0 {
+		opt.AddQuery(selection.NewRequirement("org_id", selection.Equals, args.OrgID))
+	}
+	if args.ProjectID != 0 {
+		opt.AddQuery(selection.NewRequirement("project_id", selection.Equals, args.ProjectID))
+	}
+	if args.TaskID != "" {
+		opt.AddQuery(selection.NewRequirement("task_id", selection.Equals, args.TaskID))
+	}
+	if args.UID != 0 {
+		opt.AddQuery(selection.NewRequirement("uid", selection.Equals, args.UID))
+	}
+	if args.Action != "" {
+		opt.AddQuery(selection.NewRequirement("action", selection.Equals, string(args.Action)))
+	}
+	if args.StartTime != 0 {
+		opt.AddQuery(selection.NewRequirement("create_time", selection.GreaterThan, args.StartTime))
+	}
+	if args.EndTime != 0 {
+		opt.AddQuery(selection.NewRequirement("create_time", selection.LessThan, args.EndTime))
+	}
+
+	opt.Page = args.Page
+	opt.Pagesize = args.Pagesize
+	opt.OrderBy = "id DESC"
+
+	list, err := a.store.OperationLog().GetList(opt)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取操作日志列表失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, 0, errObj
+	}
+
+	total, err := a.store.OperationLog().GetTotal(opt)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取操作日志数量失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, 0, errObj
+	}
+
+	return list, total, nil
+}
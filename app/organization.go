@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/errors"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// OrgRole 表示用户在组织内的角色，决定其能操作哪些项目
+type OrgRole string
+
+const (
+	OrgRoleOwner  OrgRole = "owner"
+	OrgRoleAdmin  OrgRole = "admin"
+	OrgRoleMember OrgRole = "member"
+)
+
+// personalOrgName 个人组织的统一名称，按 owner + 该名称精确匹配，用于兼容未接入组织体系前创建的项目
+const personalOrgName = "personal"
+
+func (a *app) CreateOrganization(ctx context.Context, tx *gorm.DB, name string, ownerUID int64) (int64, error) {
+	org := common.Organization{
+		Name:       name,
+		OwnerUID:   ownerUID,
+		CreateTime: time.Now().Unix(),
+	}
+
+	id, err := a.store.Organization().CreateOrganization(tx, org)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "创建组织失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return 0, errObj
+	}
+
+	if err = a.store.OrganizationMembership().Create(tx, common.OrganizationMembership{
+		OrganizationID: id,
+		UID:            ownerUID,
+		Role:           string(OrgRoleOwner),
+		CreateTime:     time.Now().Unix(),
+	}); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "创建组织归属关系失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return 0, errObj
+	}
+
+	return id, nil
+}
+
+// ensurePersonalOrganization 保证每个用户都拥有一个可用于存放历史项目的个人组织
+// 兼容组织体系上线前已经存在的用户和项目，避免迁移数据。
+// 查找和创建必须在同一个事务内通过行锁完成，否则两个并发的 CreateProject
+// 都可能查不到已存在的个人组织，从而各自建出一条重复记录
+func (a *app) ensurePersonalOrganization(ctx context.Context, tx *gorm.DB, uid int64) (int64, error) {
+	org, err := a.store.Organization().GetOrganizationForUpdate(tx, uid, personalOrgName)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "无法获取个人组织信息"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return 0, errObj
+	}
+	if org != nil {
+		return org.ID, nil
+	}
+
+	return a.CreateOrganization(ctx, tx, personalOrgName, uid)
+}
+
+func (a *app) GetOrganization(ctx context.Context, orgID int64) (*common.Organization, error) {
+	opt := selection.NewSelector(selection.NewRequirement("id", selection.Equals, orgID))
+	opt.Pagesize = 1
+
+	res, err := a.store.Organization().GetOrganization(opt)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "无法获取组织信息"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	if len(res) == 0 {
+		return nil, errors.ErrOrganizationNotExist
+	}
+
+	return res[0], nil
+}
+
+func (a *app) ListUserOrganizations(ctx context.Context, uid int64) ([]*common.Organization, error) {
+	opt := selection.NewSelector(selection.NewRequirement("uid", selection.Equals, uid))
+	memberships, err := a.store.OrganizationMembership().GetList(opt)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "无法获取组织归属信息"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	var orgIDs []int64
+	for _, v := range memberships {
+		orgIDs = append(orgIDs, v.OrganizationID)
+	}
+
+	if len(orgIDs) == 0 {
+		return nil, nil
+	}
+
+	opt = selection.NewSelector(selection.NewRequirement("id", selection.In, orgIDs))
+	orgs, err := a.store.Organization().GetOrganization(opt)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "无法获取组织信息"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	return orgs, nil
+}
+
+func (a *app) AddOrgMember(ctx context.Context, orgID, uid int64, role OrgRole) error {
+	if err := a.store.OrganizationMembership().Create(nil, common.OrganizationMembership{
+		OrganizationID: orgID,
+		UID:            uid,
+		Role:           string(role),
+		CreateTime:     time.Now().Unix(),
+	}); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "添加组织成员失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+func (a *app) RemoveOrgMember(ctx context.Context, orgID, uid int64) error {
+	if err := a.store.OrganizationMembership().Delete(nil, orgID, uid); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "移除组织成员失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+func (a *app) UpdateOrgMemberRole(ctx context.Context, orgID, uid int64, role OrgRole) error {
+	if err := a.store.OrganizationMembership().UpdateRole(orgID, uid, string(role)); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "更新组织成员角色失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+// getOrgMemberRole 返回用户在指定组织内的角色，用户不在组织内时返回空字符串
+func (a *app) getOrgMemberRole(ctx context.Context, orgID, uid int64) (OrgRole, error) {
+	opt := selection.NewSelector(selection.NewRequirement("organization_id", selection.Equals, orgID),
+		selection.NewRequirement("uid", selection.Equals, uid))
+	opt.Pagesize = 1
+
+	res, err := a.store.OrganizationMembership().GetList(opt)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取组织成员信息失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return "", errObj
+	}
+
+	if len(res) == 0 {
+		return "", nil
+	}
+
+	return OrgRole(res[0].Role), nil
+}
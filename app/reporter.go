@@ -0,0 +1,118 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/config"
+	"github.com/holdno/gopherCron/pkg/logger"
+	"github.com/holdno/gopherCron/pkg/store/sqlStore"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ClientTaskReporter 是 client 把任务执行结果上报出去的出口，
+// 可以是直连 MySQL（单机部署）也可以是走 HTTP 上报给独立的 server 进程
+type ClientTaskReporter interface {
+	ResultReport(result *common.TaskExecuteResult) error
+	StageReport(log common.TaskStageLog) error
+}
+
+// httpReporter 把执行结果通过 HTTP POST 上报给 server，同时兼任 Warner，
+// 告警也走同一个 server 地址上报
+type httpReporter struct {
+	addr   string
+	client *http.Client
+}
+
+// NewHttpReporter 构造一个通过 HTTP 把结果/告警上报给 server 的 ClientTaskReporter，
+// 同时实现了 Warner，NewClient 会把同一个实例赋给两者
+func NewHttpReporter(addr string) *httpReporter {
+	return &httpReporter{
+		addr:   addr,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (r *httpReporter) ResultReport(result *common.TaskExecuteResult) error {
+	return r.post("/api/v1/client/report/result", result)
+}
+
+func (r *httpReporter) Warning(data WarningData) error {
+	return r.post("/api/v1/client/report/warning", data)
+}
+
+func (r *httpReporter) StageReport(log common.TaskStageLog) error {
+	return r.post("/api/v1/client/report/stage", log)
+}
+
+func (r *httpReporter) post(path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, r.addr+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("report to %s%s failed: status=%d", r.addr, path, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// defaultTaskReporter 在没有配置独立 server 地址时使用，直接把结果写进 client 自己的 MySQL，
+// 适用于单机部署、server 和 client 共用同一个数据库的场景
+type defaultTaskReporter struct {
+	store  sqlStore.SqlStore
+	logger *logrus.Logger
+}
+
+// NewDefaultTaskReporter 构造一个直连 MySQL 的 ClientTaskReporter，install 固定为 false，
+// 建表只应该由 server 的 NewApp 负责，避免 client 和 server 各自 AutoMigrate 产生竞争
+func NewDefaultTaskReporter(l *logrus.Logger, conf *config.MysqlConf) *defaultTaskReporter {
+	if l == nil {
+		l = logger.MustSetup("info")
+	}
+	return &defaultTaskReporter{
+		store:  sqlStore.MustSetup(conf, l, false),
+		logger: l,
+	}
+}
+
+func (r *defaultTaskReporter) ResultReport(result *common.TaskExecuteResult) error {
+	withError := 0
+	if result.Err != "" {
+		withError = 1
+	}
+
+	return r.store.TaskLog().Create(common.TaskLog{
+		ProjectID: result.Task.ProjectID,
+		TaskID:    result.Task.TaskID,
+		Name:      result.Task.Name,
+		Result:    result.Result,
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		WithError: withError,
+		ClientIP:  result.Task.ClientIP,
+	})
+}
+
+func (r *defaultTaskReporter) StageReport(log common.TaskStageLog) error {
+	return r.store.TaskStageLog().Create(nil, log)
+}
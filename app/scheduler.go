@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/holdno/gopherCron/common"
+)
+
+// TaskScheduler 维护某一时刻在当前 worker 上同时触发的任务集合，
+// 负责按优先级排序、分阶段推进以及依赖就绪判断
+type TaskScheduler struct {
+	mu      sync.Mutex
+	pending map[int64][]*common.TaskInfo // projectID -> 同一触发时刻待执行的任务
+}
+
+func initScheduler() *TaskScheduler {
+	return &TaskScheduler{
+		pending: make(map[int64][]*common.TaskInfo),
+	}
+}
+
+// Enqueue 将同一时刻触发的任务加入调度队列
+func (s *TaskScheduler) Enqueue(task *common.TaskInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[task.ProjectID] = append(s.pending[task.ProjectID], task)
+}
+
+// DrainOrdered 取出某个项目当前待执行的任务，按 Priority 升序排列（0 为最高优先级），
+// 同优先级时保持入队顺序稳定
+func (s *TaskScheduler) DrainOrdered(projectID int64) []*common.TaskInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := s.pending[projectID]
+	delete(s.pending, projectID)
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Priority < tasks[j].Priority
+	})
+
+	return tasks
+}
+
+// PendingProjects 返回当前仍有待执行任务的项目 ID，供 Loop 轮询哪些项目需要 drain
+func (s *TaskScheduler) PendingProjects() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects := make([]int64, 0, len(s.pending))
+	for pid := range s.pending {
+		projects = append(projects, pid)
+	}
+
+	return projects
+}
+
+// dependenciesReady 判断某个任务的所有前置依赖是否都已在本轮触发窗口内成功执行过
+func (c *client) dependenciesReady(ctx context.Context, task *common.TaskInfo, windowStart int64) (bool, error) {
+	for _, depTaskID := range task.DependsOn {
+		successAt, err := c.lastSuccessTime(ctx, task.ProjectID, depTaskID)
+		if err != nil {
+			return false, err
+		}
+		if successAt < windowStart {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// lastSuccessTime 从 etcd 中读取依赖任务最近一次成功执行的时间戳
+func (c *client) lastSuccessTime(ctx context.Context, pid int64, tid string) (int64, error) {
+	key := common.BuildTaskSuccessKey(pid, tid)
+	resp, err := c.etcd.KV().Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, nil
+	}
+
+	return common.ParseUnixTimestamp(resp.Kvs[0].Value)
+}
+
+// markTaskSuccess 把任务本次成功执行的时间戳写入 etcd，供依赖它的下游任务的
+// dependenciesReady 判断使用
+func (c *client) markTaskSuccess(ctx context.Context, task *common.TaskInfo, at int64) error {
+	key := common.BuildTaskSuccessKey(task.ProjectID, task.TaskID)
+	_, err := c.etcd.KV().Put(ctx, key, fmt.Sprintf("%d", at))
+	return err
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/errors"
+
+	"github.com/holdno/gocommons/selection"
+)
+
+// validateTaskStages 校验阶段编排的合法性：必须非空命令，planCompletedAt 不得早于前一个阶段（允许相等）
+func validateTaskStages(stages []common.TaskStage) error {
+	var prevPlan int64
+	for i, stage := range stages {
+		if stage.Command == "" {
+			return fmt.Errorf("stage[%d] command is empty", i)
+		}
+		if i > 0 && stage.PlanCompletedAt < prevPlan {
+			return fmt.Errorf("stage[%d] plan_completed_at must not be earlier than the previous stage", i)
+		}
+		prevPlan = stage.PlanCompletedAt
+	}
+
+	return nil
+}
+
+// detectDependencyCycle 在同一项目内沿 DependsOn 做一次 DFS，检测是否会引入环
+func (a *app) detectDependencyCycle(ctx context.Context, pid int64, tid string, dependsOn []string) error {
+	tasks, err := a.GetTaskList(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	graph := make(map[string][]string, len(tasks)+1)
+	for _, t := range tasks {
+		graph[t.TaskID] = t.DependsOn
+	}
+	graph[tid] = dependsOn
+
+	visiting := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var dfs func(id string) error
+	dfs = func(id string) error {
+		if visiting[id] {
+			return fmt.Errorf("dependency cycle detected at task %s", id)
+		}
+		if visited[id] {
+			return nil
+		}
+
+		visiting[id] = true
+		for _, dep := range graph[id] {
+			if err := dfs(dep); err != nil {
+				return err
+			}
+		}
+		visiting[id] = false
+		visited[id] = true
+		return nil
+	}
+
+	return dfs(tid)
+}
+
+func (a *app) SaveTask(ctx context.Context, task *common.TaskInfo) (*common.TaskInfo, error) {
+	if err := validateTaskStages(task.Stages); err != nil {
+		errObj := errors.ErrDataParseError
+		errObj.Msg = "任务阶段编排不合法"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	if len(task.DependsOn) > 0 {
+		if err := a.detectDependencyCycle(ctx, task.ProjectID, task.TaskID, task.DependsOn); err != nil {
+			errObj := errors.ErrDataParseError
+			errObj.Msg = "任务依赖关系存在环"
+			errObj.Log = err.Error()
+			errObj.TraceID = TraceIDFromContext(ctx)
+			return nil, errObj
+		}
+	}
+
+	before, _ := a.GetTask(ctx, task.ProjectID, task.TaskID)
+
+	result, err := a.store.Task().Save(*task)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "保存任务失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: task.ProjectID,
+		TaskID:    task.TaskID,
+		Action:    OperationActionSaveTask,
+		Target:    task.Name,
+		Before:    before,
+		After:     result,
+		ClientIP:  a.GetIP(),
+	})
+
+	return result, nil
+}
+
+// saveTaskStageLog 记录单个阶段的执行结果，runID 用于将同一次触发的所有阶段串联起来
+func (a *app) saveTaskStageLog(ctx context.Context, log common.TaskStageLog) error {
+	if err := a.store.TaskStageLog().Create(nil, log); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "保存任务阶段日志失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+func (a *app) GetTaskList(ctx context.Context, projectID int64) ([]*common.TaskInfo, error) {
+	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, projectID))
+
+	list, err := a.store.Task().GetList(opt)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取任务列表失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	return list, nil
+}
+
+func (a *app) GetTask(ctx context.Context, projectID int64, nameID string) (*common.TaskInfo, error) {
+	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, projectID),
+		selection.NewRequirement("task_id", selection.Equals, nameID))
+	opt.Pagesize = 1
+
+	list, err := a.store.Task().GetList(opt)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取任务信息失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return list[0], nil
+}
+
+// getTaskLastSuccessTime 从任务日志中读取某个任务最近一次成功执行（with_error = 0）的开始时间戳，
+// 不按调度窗口过滤，没有成功记录时返回 0
+func (a *app) getTaskLastSuccessTime(ctx context.Context, pid int64, tid string) (int64, error) {
+	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid),
+		selection.NewRequirement("task_id", selection.Equals, tid),
+		selection.NewRequirement("with_error", selection.Equals, 0))
+	opt.Pagesize = 1
+	opt.OrderBy = "start_time DESC"
+
+	list, err := a.store.TaskLog().GetList(opt)
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+
+	return list[0].StartTime, nil
+}
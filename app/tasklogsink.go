@@ -0,0 +1,577 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/config"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// sinkHTTPClient 供 ES/S3 的直接 HTTP 调用共用，统一超时时间避免 sink 写入阻塞任务执行
+var sinkHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// TaskLogSink 是任务日志的落地出口，MySQL 之外的存储（ES/Kafka/S3...）都通过它接入，
+// 彼此之间互不感知，client 侧只认 TaskLogSink 这一层抽象
+type TaskLogSink interface {
+	WriteLog(ctx context.Context, log *common.TaskLog) error
+	WriteWarning(ctx context.Context, warning WarningData) error
+	Flush(ctx context.Context) error
+	Close() error
+}
+
+// MultiSink 把一条日志广播给多个 TaskLogSink，任意一个失败不影响其他 sink 的写入
+type MultiSink struct {
+	sinks []TaskLogSink
+}
+
+func NewMultiSink(sinks ...TaskLogSink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+func (m *MultiSink) WriteLog(ctx context.Context, log *common.TaskLog) error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.WriteLog(ctx, log); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *MultiSink) WriteWarning(ctx context.Context, warning WarningData) error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.WriteWarning(ctx, warning); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *MultiSink) Flush(ctx context.Context) error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.Flush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *MultiSink) Close() error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// diskSpool 是所有 sink 共用的兜底能力：下游不可用时把日志原样落盘，
+// 保证任务执行本身永远不会因为日志写入而阻塞或失败
+type diskSpool struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func newDiskSpool(dir string) *diskSpool {
+	return &diskSpool{dir: dir}
+}
+
+func (d *diskSpool) write(name string, data []byte) error {
+	if d.dir == "" {
+		return fmt.Errorf("disk spool directory is not configured")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(d.dir, name), data, 0644)
+}
+
+// esBulkBuffer 按条数和时间双重阈值触发 flush，避免小请求拖垮 Elasticsearch
+type esBulkBuffer struct {
+	mu            sync.Mutex
+	docs          []map[string]interface{}
+	maxSize       int
+	flushInterval time.Duration
+	lastFlush     time.Time
+}
+
+// ESSink 把任务日志写入按天滚动的索引 gophercron-tasklog-YYYY.MM.DD
+type ESSink struct {
+	addr      []string
+	indexName func(t time.Time) string
+	buffer    *esBulkBuffer
+	spool     *diskSpool
+}
+
+// NewESSink bulkSize/flushInterval 任意超过即触发一次 bulk 写入
+func NewESSink(addr []string, bulkSize int, flushInterval time.Duration, spoolDir string) *ESSink {
+	return &ESSink{
+		addr: addr,
+		indexName: func(t time.Time) string {
+			return fmt.Sprintf("gophercron-tasklog-%s", t.Format("2006.01.02"))
+		},
+		buffer: &esBulkBuffer{
+			maxSize:       bulkSize,
+			flushInterval: flushInterval,
+			lastFlush:     time.Now(),
+		},
+		spool: newDiskSpool(spoolDir),
+	}
+}
+
+func (s *ESSink) WriteLog(ctx context.Context, log *common.TaskLog) error {
+	doc := map[string]interface{}{
+		"_index": s.indexName(time.Now()),
+		"body":   log,
+	}
+
+	s.buffer.mu.Lock()
+	s.buffer.docs = append(s.buffer.docs, doc)
+	needFlush := len(s.buffer.docs) >= s.buffer.maxSize || time.Since(s.buffer.lastFlush) >= s.buffer.flushInterval
+	s.buffer.mu.Unlock()
+
+	if needFlush {
+		return s.Flush(ctx)
+	}
+
+	return nil
+}
+
+func (s *ESSink) WriteWarning(ctx context.Context, warning WarningData) error {
+	// 告警量级很小，直接走 bulk 缓冲即可，不单独开索引
+	return s.WriteLog(ctx, &common.TaskLog{
+		Result: warning.Data,
+	})
+}
+
+func (s *ESSink) Flush(ctx context.Context) error {
+	s.buffer.mu.Lock()
+	docs := s.buffer.docs
+	s.buffer.docs = nil
+	s.buffer.lastFlush = time.Now()
+	s.buffer.mu.Unlock()
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	if err := s.bulkIndex(ctx, docs); err != nil {
+		// ES 不可用时落盘，避免日志丢失，等待后续人工/脚本补写
+		data, merr := json.Marshal(docs)
+		if merr == nil {
+			_ = s.spool.write(fmt.Sprintf("es-spool-%d.json", time.Now().UnixNano()), data)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// bulkIndex 通过 Elasticsearch 的 `_bulk` HTTP 接口批量写入，NDJSON 每条文档占两行：
+// 一行 index 元信息，一行文档内容本身
+func (s *ESSink) bulkIndex(ctx context.Context, docs []map[string]interface{}) error {
+	if len(s.addr) == 0 {
+		return fmt.Errorf("elasticsearch sink is not configured with any address")
+	}
+
+	var body bytes.Buffer
+	for _, doc := range docs {
+		meta, err := json.Marshal(map[string]interface{}{
+			"index": map[string]interface{}{"_index": doc["_index"]},
+		})
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(doc["body"])
+		if err != nil {
+			return err
+		}
+		body.Write(meta)
+		body.WriteByte('\n')
+		body.Write(payload)
+		body.WriteByte('\n')
+	}
+
+	var lastErr error
+	for _, addr := range s.addr {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(addr, "/")+"/_bulk",
+			bytes.NewReader(body.Bytes()))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+
+		resp, err := sinkHTTPClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			lastErr = fmt.Errorf("elasticsearch bulk request to %s failed: status=%d body=%s", addr, resp.StatusCode, respBody)
+			continue
+		}
+
+		return nil
+	}
+
+	return lastErr
+}
+
+func (s *ESSink) Close() error {
+	return s.Flush(context.Background())
+}
+
+// esLogReader 让 server 端可以直接查询 ESSink 写入的 task_log 文档（索引按天滚动，
+// 统一用 gophercron-tasklog-* 通配符检索），使 GetTaskLogList/GetTaskLogTotal 在配置了
+// Elasticsearch sink 时不再依赖 MySQL 里的 gc_task_log 表
+type esLogReader struct {
+	addr []string
+}
+
+// newESLogReader 在未配置 Elasticsearch sink 时返回 nil，调用方据此判断是否退回 MySQL
+func newESLogReader(conf *config.ElasticsearchConf) *esLogReader {
+	if conf == nil || len(conf.Addr) == 0 {
+		return nil
+	}
+	return &esLogReader{addr: conf.Addr}
+}
+
+func taskLogSearchFilter(pid int64, tid string) []map[string]interface{} {
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"body.project_id": pid}},
+	}
+	if tid != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"body.task_id.keyword": tid}})
+	}
+	return filters
+}
+
+// search 依次尝试各个配置的地址，直到有一个返回成功
+func (r *esLogReader) search(ctx context.Context, body map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, addr := range r.addr {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+			strings.TrimRight(addr, "/")+"/gophercron-tasklog-*/_search", bytes.NewReader(data))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := sinkHTTPClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			lastErr = fmt.Errorf("elasticsearch search request to %s failed: status=%d body=%s", addr, resp.StatusCode, respBody)
+			continue
+		}
+
+		var result map[string]interface{}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			lastErr = err
+			continue
+		}
+		return result, nil
+	}
+
+	return nil, lastErr
+}
+
+func (r *esLogReader) GetList(ctx context.Context, pid int64, tid string, page, pagesize int) ([]*common.TaskLog, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pagesize <= 0 {
+		pagesize = 20
+	}
+
+	result, err := r.search(ctx, map[string]interface{}{
+		"from":  (page - 1) * pagesize,
+		"size":  pagesize,
+		"sort":  []map[string]interface{}{{"body.start_time": map[string]interface{}{"order": "desc"}}},
+		"query": map[string]interface{}{"bool": map[string]interface{}{"filter": taskLogSearchFilter(pid, tid)}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTaskLogSearchHits(result)
+}
+
+func (r *esLogReader) GetTotal(ctx context.Context, pid int64, tid string) (int, error) {
+	result, err := r.search(ctx, map[string]interface{}{
+		"size":  0,
+		"query": map[string]interface{}{"bool": map[string]interface{}{"filter": taskLogSearchFilter(pid, tid)}},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	hits, _ := result["hits"].(map[string]interface{})
+	total, _ := hits["total"].(map[string]interface{})
+	value, _ := total["value"].(float64)
+	return int(value), nil
+}
+
+func parseTaskLogSearchHits(result map[string]interface{}) ([]*common.TaskLog, error) {
+	hits, _ := result["hits"].(map[string]interface{})
+	hitList, _ := hits["hits"].([]interface{})
+
+	list := make([]*common.TaskLog, 0, len(hitList))
+	for _, h := range hitList {
+		hit, ok := h.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		source, ok := hit["_source"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		body, ok := source["body"]
+		if !ok {
+			continue
+		}
+
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		var log common.TaskLog
+		if err := json.Unmarshal(data, &log); err != nil {
+			return nil, err
+		}
+		list = append(list, &log)
+	}
+
+	return list, nil
+}
+
+// KafkaSink 把日志以 `project_id:task_id` 为 key 发布为 JSON 消息，供下游自行消费
+type KafkaSink struct {
+	brokers []string
+	topic   string
+	writer  *kafka.Writer
+	spool   *diskSpool
+}
+
+func NewKafkaSink(brokers []string, topic string, spoolDir string) *KafkaSink {
+	sink := &KafkaSink{brokers: brokers, topic: topic, spool: newDiskSpool(spoolDir)}
+
+	if len(brokers) > 0 {
+		sink.writer = &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			BatchTimeout: time.Millisecond * 100,
+		}
+	}
+
+	return sink
+}
+
+type kafkaEnvelope struct {
+	Key     string          `json:"key"`
+	Payload *common.TaskLog `json:"payload"`
+}
+
+func (k *KafkaSink) WriteLog(ctx context.Context, log *common.TaskLog) error {
+	envelope := kafkaEnvelope{
+		Key:     fmt.Sprintf("%d:%s", log.ProjectID, log.TaskID),
+		Payload: log,
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	if err := k.publish(ctx, envelope.Key, data); err != nil {
+		_ = k.spool.write(fmt.Sprintf("kafka-spool-%d.json", time.Now().UnixNano()), data)
+		return err
+	}
+
+	return nil
+}
+
+func (k *KafkaSink) WriteWarning(ctx context.Context, warning WarningData) error {
+	data, err := json.Marshal(warning)
+	if err != nil {
+		return err
+	}
+	return k.publish(ctx, warning.AgentIP, data)
+}
+
+// publish 通过 kafka-go 的 Writer 把消息发布到配置的 topic，key 用于保证同一任务的消息
+// 落在同一个分区，保持顺序
+func (k *KafkaSink) publish(ctx context.Context, key string, data []byte) error {
+	if k.writer == nil {
+		return fmt.Errorf("kafka sink is not configured with any broker")
+	}
+
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(key),
+		Value: data,
+	})
+}
+
+func (k *KafkaSink) Flush(ctx context.Context) error { return nil }
+
+func (k *KafkaSink) Close() error {
+	if k.writer == nil {
+		return nil
+	}
+	return k.writer.Close()
+}
+
+// S3Sink 把一分钟内、同一个 worker 产生的日志打包成一个 gzip NDJSON 对象，减少小文件数量
+type S3Sink struct {
+	endpoint   string
+	bucket     string
+	prefix     string
+	workerIP   string
+	mu         sync.Mutex
+	buffer     []byte
+	windowEnds time.Time
+	spool      *diskSpool
+}
+
+func NewS3Sink(endpoint, bucket, prefix, workerIP string, spoolDir string) *S3Sink {
+	return &S3Sink{
+		endpoint:   endpoint,
+		bucket:     bucket,
+		prefix:     prefix,
+		workerIP:   workerIP,
+		windowEnds: time.Now().Add(time.Minute),
+		spool:      newDiskSpool(spoolDir),
+	}
+}
+
+func (s *S3Sink) WriteLog(ctx context.Context, log *common.TaskLog) error {
+	line, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.buffer = append(append(s.buffer, line...), '\n')
+	shouldFlush := time.Now().After(s.windowEnds)
+	s.mu.Unlock()
+
+	if shouldFlush {
+		return s.Flush(ctx)
+	}
+
+	return nil
+}
+
+func (s *S3Sink) WriteWarning(ctx context.Context, warning WarningData) error {
+	line, err := json.Marshal(warning)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.buffer = append(append(s.buffer, line...), '\n')
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *S3Sink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	data := s.buffer
+	s.buffer = nil
+	s.windowEnds = time.Now().Add(time.Minute)
+	s.mu.Unlock()
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s/%s/%d.ndjson.gz", s.prefix, s.workerIP, time.Now().Unix())
+	if err := s.putObject(ctx, key, gz.Bytes()); err != nil {
+		_ = s.spool.write(fmt.Sprintf("s3-spool-%d.ndjson.gz", time.Now().UnixNano()), gz.Bytes())
+		return err
+	}
+
+	return nil
+}
+
+// putObject 用不带签名的 HTTP PUT 写入兼容 S3 协议的对象存储（如内部 minio 网关），
+// 真正面向 AWS S3 的部署需要加上 SigV4 签名，届时在这里接入即可
+func (s *S3Sink) putObject(ctx context.Context, key string, data []byte) error {
+	if s.bucket == "" {
+		return fmt.Errorf("s3 sink is not configured with a bucket")
+	}
+	if s.endpoint == "" {
+		return fmt.Errorf("s3 sink: endpoint not configured, spooling object %q instead", key)
+	}
+
+	url := fmt.Sprintf("%s/%s/%s", strings.TrimRight(s.endpoint, "/"), s.bucket, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+
+	resp, err := sinkHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("s3 put object %s failed: status=%d body=%s", url, resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+func (s *S3Sink) Close() error {
+	return s.Flush(context.Background())
+}
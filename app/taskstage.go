@@ -0,0 +1,29 @@
+package app
+
+import (
+	"context"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/errors"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+func (a *app) GetTaskStageLogs(ctx context.Context, pid int64, tid string, runID string) ([]*common.TaskStageLog, error) {
+	opt := selection.NewSelector(selection.NewRequirement("project_id", selection.Equals, pid),
+		selection.NewRequirement("task_id", selection.Equals, tid),
+		selection.NewRequirement("run_id", selection.Equals, runID))
+	opt.OrderBy = "stage_index ASC"
+
+	list, err := a.store.TaskStageLog().GetList(opt)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取任务阶段日志失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	return list, nil
+}
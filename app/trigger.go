@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// drainInterval 是 Loop 轮询 scheduler 中待执行项目的间隔，
+// 给同一触发时刻到达的多个任务留出时间窗口去合并进同一批 DrainOrdered
+const drainInterval = 200 * time.Millisecond
+
+// Loop 是 client 的主循环：消费 Trigger 推送的任务，按项目合批、按优先级排序后依次执行各阶段，
+// 执行前会检查依赖是否就绪，成功后把完成时间写回 etcd 供下游任务判断依赖
+func (c *client) Loop() {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case task, ok := <-c.Trigger():
+			if !ok {
+				return
+			}
+			c.scheduler.Enqueue(task)
+		case <-ticker.C:
+			for _, pid := range c.scheduler.PendingProjects() {
+				for _, task := range c.scheduler.DrainOrdered(pid) {
+					c.Go(func(a ...interface{}) {
+						c.runTask(a[0].(*common.TaskInfo))
+					})(task)
+				}
+			}
+		}
+	}
+}
+
+// runTask 执行一个任务的全部阶段，任一阶段失败则不再继续后续阶段。
+// runID 同时充当这次触发的 trace id，串联起依赖检查、阶段执行等全部 etcd/上报调用的日志
+func (c *client) runTask(task *common.TaskInfo) {
+	runID, err := utils.GetID()
+	if err != nil {
+		c.logger.WithFields(logrus.Fields{
+			"project_id": task.ProjectID,
+			"task_id":    task.TaskID,
+			"error":      err.Error(),
+		}).Error("failed to generate run id")
+		return
+	}
+	traceID := fmt.Sprintf("%d", runID)
+	ctx := ContextWithTraceID(context.Background(), traceID)
+
+	windowStart := time.Now().Unix()
+
+	ready, err := c.dependenciesReady(ctx, task, windowStart)
+	if err != nil {
+		if l := c.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"project_id": task.ProjectID,
+				"task_id":    task.TaskID,
+				"error":      err.Error(),
+			}).Error("failed to check task dependencies")
+		}
+		return
+	}
+	if !ready {
+		if l := c.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"project_id": task.ProjectID,
+				"task_id":    task.TaskID,
+			}).Warn("task dependencies not ready, skip this trigger")
+		}
+		return
+	}
+
+	if err := c.setTaskRunning(ctx, task); err != nil {
+		if l := c.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"project_id": task.ProjectID,
+				"task_id":    task.TaskID,
+				"error":      err.Error(),
+			}).Error("failed to mark task running")
+		}
+	}
+	defer func() {
+		if err := c.setTaskNotRunning(ctx, task); err != nil {
+			if l := c.slog.withTraceID(ctx); l != nil {
+				l.WithFields(logrus.Fields{
+					"project_id": task.ProjectID,
+					"task_id":    task.TaskID,
+					"error":      err.Error(),
+				}).Error("failed to clear task running state")
+			}
+		}
+	}()
+
+	stages := task.Stages
+	if len(stages) == 0 {
+		stages = []common.TaskStage{{Command: task.Command}}
+	}
+
+	succeeded := true
+	for i, stage := range stages {
+		if !c.runStage(ctx, task, traceID, i, stage) {
+			succeeded = false
+			break
+		}
+	}
+
+	if succeeded {
+		if err := c.markTaskSuccess(ctx, task, time.Now().Unix()); err != nil {
+			if l := c.slog.withTraceID(ctx); l != nil {
+				l.WithFields(logrus.Fields{
+					"project_id": task.ProjectID,
+					"task_id":    task.TaskID,
+					"error":      err.Error(),
+				}).Error("failed to mark task success in etcd")
+			}
+		}
+	}
+}
+
+// setTaskRunning/setTaskNotRunning 维护 client 自身在 etcd 上的运行状态标记，
+// 复用 server 侧 GetWorkerList 读取的同一个 key 前缀约定（见 worker.go 的 taskRunningKey）
+func (c *client) setTaskRunning(ctx context.Context, task *common.TaskInfo) error {
+	key := taskRunningKey("", task.ProjectID, task.TaskID)
+	_, err := c.etcd.KV().Put(ctx, key, c.localip)
+	return err
+}
+
+func (c *client) setTaskNotRunning(ctx context.Context, task *common.TaskInfo) error {
+	key := taskRunningKey("", task.ProjectID, task.TaskID)
+	_, err := c.etcd.KV().Delete(ctx, key)
+	return err
+}
+
+// runStage 执行单个阶段并上报结果，返回值表示该阶段是否成功
+func (c *client) runStage(ctx context.Context, task *common.TaskInfo, runID string, index int, stage common.TaskStage) bool {
+	start := time.Now().Unix()
+
+	cmd := exec.Command("/bin/sh", "-c", stage.Command)
+	output, execErr := cmd.CombinedOutput()
+
+	end := time.Now().Unix()
+	withError := 0
+	errMsg := ""
+	if execErr != nil {
+		withError = 1
+		errMsg = execErr.Error()
+	}
+
+	log := common.TaskStageLog{
+		ProjectID:  task.ProjectID,
+		TaskID:     task.TaskID,
+		RunID:      runID,
+		StageIndex: index,
+		Command:    stage.Command,
+		Result:     string(output),
+		StartTime:  start,
+		EndTime:    end,
+		WithError:  withError,
+	}
+
+	if err := c.StageReport(log); err != nil {
+		if l := c.slog.withTraceID(ctx); l != nil {
+			l.WithFields(logrus.Fields{
+				"project_id": task.ProjectID,
+				"task_id":    task.TaskID,
+				"run_id":     runID,
+				"stage":      index,
+				"error":      err.Error(),
+			}).Error("failed to report task stage log")
+		}
+	}
+
+	if execErr != nil {
+		if err := c.Warning(WarningData{
+			Data:    fmt.Sprintf("task %d/%s stage[%d] failed: %s", task.ProjectID, task.TaskID, index, errMsg),
+			Type:    WarningTypeTask,
+			AgentIP: c.localip,
+			TraceID: TraceIDFromContext(ctx),
+		}); err != nil {
+			if l := c.slog.withTraceID(ctx); l != nil {
+				l.WithFields(logrus.Fields{
+					"project_id": task.ProjectID,
+					"task_id":    task.TaskID,
+					"error":      err.Error(),
+				}).Error("failed to send task stage warning")
+			}
+		}
+	}
+
+	return execErr == nil
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// WarningType 区分告警的来源子系统，便于接收端分类路由
+type WarningType string
+
+const (
+	WarningTypeSystem WarningType = "system" // panic、日志投递失败等系统级异常
+	WarningTypeTask   WarningType = "task"   // 任务执行本身产生的异常
+)
+
+// WarningData 描述一条告警的完整上下文
+type WarningData struct {
+	Data    string      `json:"data"`
+	Type    WarningType `json:"type"`
+	AgentIP string      `json:"agent_ip"`
+	// TraceID 为空表示告警的产生方没有串联的 trace id 可带（例如 panicgroup 捕获的裸 panic）
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+// Warner 是告警的统一出口，server 和 client 各自可以有不同的实现
+// （打日志、发 webhook、转发给 TaskLogSink 等）
+type Warner interface {
+	Warning(data WarningData) error
+}
+
+// defaultWarner 没有配置任何外部告警通道时的兜底实现，只把告警写进日志
+type defaultWarner struct {
+	logger *logrus.Logger
+}
+
+// NewDefaultWarner 构造一个只打日志的 Warner，作为未显式配置告警通道时的兜底
+func NewDefaultWarner(logger *logrus.Logger) Warner {
+	return &defaultWarner{logger: logger}
+}
+
+func (w *defaultWarner) Warning(data WarningData) error {
+	w.logger.WithFields(logrus.Fields{
+		"type":     data.Type,
+		"agent_ip": data.AgentIP,
+		"trace_id": data.TraceID,
+	}).Warn(data.Data)
+	return nil
+}
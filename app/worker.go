@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/errors"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/holdno/gocommons/selection"
+)
+
+func (a *app) DeleteTask(ctx context.Context, pid int64, tid string) (*common.TaskInfo, error) {
+	before, _ := a.GetTask(ctx, pid, tid)
+
+	if err := a.store.Task().Delete(pid, tid); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "删除任务失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: pid,
+		TaskID:    tid,
+		Action:    OperationActionDeleteTask,
+		Before:    before,
+		ClientIP:  a.GetIP(),
+	})
+
+	return before, nil
+}
+
+// taskRunningKey 是某个任务当前正在运行时，在 etcd 上持有的标记，
+// client 执行前写入、执行结束后删除，GetWorkerList 据此判断运行状态
+func taskRunningKey(prefix string, pid int64, tid string) string {
+	return fmt.Sprintf("%s/running/%d/%s", prefix, pid, tid)
+}
+
+func (a *app) SetTaskRunning(ctx context.Context, task common.TaskInfo) error {
+	key := taskRunningKey(a.conf.Etcd.Prefix, task.ProjectID, task.TaskID)
+	if _, err := a.etcd.KV().Put(ctx, key, a.GetIP()); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "标记任务运行状态失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+func (a *app) SetTaskNotRunning(ctx context.Context, task common.TaskInfo) error {
+	key := taskRunningKey(a.conf.Etcd.Prefix, task.ProjectID, task.TaskID)
+	if _, err := a.etcd.KV().Delete(ctx, key); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "清除任务运行状态失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	return nil
+}
+
+// KillTask 把强杀请求写到 etcd 上的 <prefix>/kill/<project_id>/<task_id>，带 10 秒租约；
+// 本包的 client 不监听这个前缀，也不会向正在运行的任务进程发送信号
+func (a *app) KillTask(ctx context.Context, pid int64, tid string) error {
+	lease, err := a.etcd.Lease().Grant(ctx, 10)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "发送强杀任务请求失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	key := fmt.Sprintf("%s/kill/%d/%s", a.conf.Etcd.Prefix, pid, tid)
+	if _, err := a.etcd.KV().Put(ctx, key, "1", clientv3.WithLease(lease.ID)); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "发送强杀任务请求失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: pid,
+		TaskID:    tid,
+		Action:    OperationActionKillTask,
+		ClientIP:  a.GetIP(),
+	})
+
+	return nil
+}
+
+// GetWorkerList 读取 etcd 上该项目下所有正在运行任务的 worker IP，
+// 是"该项目当前有哪些节点在跑任务"的近似视图
+func (a *app) GetWorkerList(ctx context.Context, projectID int64) ([]string, error) {
+	prefix := fmt.Sprintf("%s/running/%d/", a.conf.Etcd.Prefix, projectID)
+	resp, err := a.etcd.KV().Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取worker列表失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	seen := make(map[string]bool, len(resp.Kvs))
+	var ips []string
+	for _, kv := range resp.Kvs {
+		ip := string(kv.Value)
+		if !seen[ip] {
+			seen[ip] = true
+			ips = append(ips, ip)
+		}
+	}
+
+	return ips, nil
+}
+
+func (a *app) GetProjectTaskCount(ctx context.Context, projectID int64) (int64, error) {
+	tasks, err := a.GetTaskList(ctx, projectID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(tasks)), nil
+}
+
+// GetMonitor 返回某个 worker 节点当前的存活状态，通过它在 etcd 上的注册 key 是否存在判断
+func (a *app) GetMonitor(ctx context.Context, ip string) (*common.MonitorInfo, error) {
+	key := fmt.Sprintf("%s/worker/%s", a.conf.Etcd.Prefix, ip)
+	resp, err := a.etcd.KV().Get(ctx, key)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "获取节点状态失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return nil, errObj
+	}
+
+	status := "offline"
+	if len(resp.Kvs) > 0 {
+		status = "online"
+	}
+
+	return &common.MonitorInfo{IP: ip, Status: status}, nil
+}
+
+// TemporarySchedulerTask 立即触发一次任务执行，不依赖 cron 表达式的调度窗口，
+// 通过写入与正常触发共用的 /trigger 前缀实现，client 侧的 TaskWatcher 会和定时触发一样处理
+func (a *app) TemporarySchedulerTask(ctx context.Context, task *common.TaskInfo) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "触发临时任务失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	key := fmt.Sprintf("%s/trigger/%d/%s", a.conf.Etcd.Prefix, task.ProjectID, task.TaskID)
+	if _, err := a.etcd.KV().Put(ctx, key, string(data)); err != nil {
+		errObj := errors.ErrInternalError
+		errObj.Msg = "触发临时任务失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	a.recordOperationLog(ctx, OperationLogEntry{
+		ProjectID: task.ProjectID,
+		TaskID:    task.TaskID,
+		Action:    OperationActionTemporaryTask,
+		ClientIP:  a.GetIP(),
+	})
+
+	return nil
+}
+
+// DeleteAll 在一个事务内清空所有项目（不区分账号）的任务日志，项目、任务、用户等其他数据不会被删除；
+// 仅用于测试环境清理，生产环境不应该暴露这个接口
+func (a *app) DeleteAll(ctx context.Context) error {
+	tx := a.store.BeginTx()
+
+	projects, err := a.store.Project().GetProject(selection.NewSelector())
+	if err != nil {
+		tx.Rollback()
+		errObj := errors.ErrInternalError
+		errObj.Msg = "清空数据失败"
+		errObj.Log = err.Error()
+		errObj.TraceID = TraceIDFromContext(ctx)
+		return errObj
+	}
+
+	for _, p := range projects {
+		if err := a.CleanProjectLog(ctx, tx, p.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,14 @@
+package common
+
+// APP_KEY 是 App 实例挂在 gin.Context 上的 key
+const APP_KEY = "gophercron_app"
+
+// CLUSTER_AUTO_INDEX 是集群自增 ID 在 etcd 中的 key 后缀，用于给每个 client 节点分配雪花算法的 worker id
+const CLUSTER_AUTO_INDEX = "/cluster/auto_index"
+
+// TRACE_ID_KEY 是 trace id 在 gin.Context 中的 key
+const TRACE_ID_KEY = "trace_id"
+
+// taskSuccessKeyPrefix 是任务最近一次成功执行时间戳在 etcd 中的 key 前缀，
+// 供依赖它的下游任务判断依赖是否就绪
+const taskSuccessKeyPrefix = "/gophercron/task_success"
@@ -0,0 +1,17 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// BuildTaskSuccessKey 返回某个任务最近一次成功执行时间戳在 etcd 中的 key，
+// 供依赖它的下游任务判断依赖是否在当前调度窗口内就绪
+func BuildTaskSuccessKey(projectID int64, taskID string) string {
+	return fmt.Sprintf("%s/%d/%s", taskSuccessKeyPrefix, projectID, taskID)
+}
+
+// ParseUnixTimestamp 解析 etcd value 中存储的 unix 时间戳
+func ParseUnixTimestamp(value []byte) (int64, error) {
+	return strconv.ParseInt(string(value), 10, 64)
+}
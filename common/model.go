@@ -0,0 +1,168 @@
+package common
+
+// Project 对应一个业务方接入的任务项目
+type Project struct {
+	ID               int64  `json:"id" gorm:"column:id;primary_key"`
+	Uid              int64  `json:"uid" gorm:"column:uid"`
+	OrganizationID   int64  `json:"organization_id" gorm:"column:organization_id"`
+	Title            string `json:"title" gorm:"column:title"`
+	Remark           string `json:"remark" gorm:"column:remark"`
+	LogRetentionDays int    `json:"log_retention_days" gorm:"column:log_retention_days"`
+	CreateTime       int64  `json:"create_time" gorm:"column:create_time"`
+}
+
+func (Project) TableName() string {
+	return "gc_project"
+}
+
+// ProjectRelevance 是历史遗留的项目成员关联表，组织体系上线前通过它判断用户与项目的归属关系
+type ProjectRelevance struct {
+	ID         int64 `json:"id" gorm:"column:id;primary_key"`
+	ProjectID  int64 `json:"project_id" gorm:"column:project_id"`
+	UID        int64 `json:"uid" gorm:"column:uid"`
+	CreateTime int64 `json:"create_time" gorm:"column:create_time"`
+}
+
+func (ProjectRelevance) TableName() string {
+	return "gc_project_relevance"
+}
+
+// Organization 是项目之上的团队/租户层
+type Organization struct {
+	ID         int64  `json:"id" gorm:"column:id;primary_key"`
+	Name       string `json:"name" gorm:"column:name"`
+	OwnerUID   int64  `json:"owner_uid" gorm:"column:owner_uid"`
+	CreateTime int64  `json:"create_time" gorm:"column:create_time"`
+}
+
+func (Organization) TableName() string {
+	return "gc_organization"
+}
+
+// OrganizationMembership 描述用户与组织的归属及角色
+type OrganizationMembership struct {
+	ID             int64  `json:"id" gorm:"column:id;primary_key"`
+	OrganizationID int64  `json:"organization_id" gorm:"column:organization_id"`
+	UID            int64  `json:"uid" gorm:"column:uid"`
+	Role           string `json:"role" gorm:"column:role"`
+	CreateTime     int64  `json:"create_time" gorm:"column:create_time"`
+}
+
+func (OrganizationMembership) TableName() string {
+	return "gc_organization_membership"
+}
+
+// OperationLog 是项目/任务/用户维度写操作的审计记录，只追加不修改
+type OperationLog struct {
+	ID         int64  `json:"id" gorm:"column:id;primary_key"`
+	OrgID      int64  `json:"org_id" gorm:"column:org_id"`
+	ProjectID  int64  `json:"project_id" gorm:"column:project_id"`
+	TaskID     string `json:"task_id" gorm:"column:task_id"`
+	UID        int64  `json:"uid" gorm:"column:uid"`
+	Action     string `json:"action" gorm:"column:action"`
+	Target     string `json:"target" gorm:"column:target"`
+	BeforeJSON string `json:"before_json" gorm:"column:before_json"`
+	AfterJSON  string `json:"after_json" gorm:"column:after_json"`
+	ClientIP   string `json:"client_ip" gorm:"column:client_ip"`
+	UserAgent  string `json:"user_agent" gorm:"column:user_agent"`
+	CreateTime int64  `json:"create_time" gorm:"column:create_time"`
+}
+
+func (OperationLog) TableName() string {
+	return "gc_operation_log"
+}
+
+// TaskStage 描述任务的一个执行阶段，多个阶段按顺序串行执行
+type TaskStage struct {
+	Command         string `json:"command"`
+	PlanCompletedAt int64  `json:"plan_completed_at"`
+}
+
+// TaskInfo 是一个定时任务的完整定义
+type TaskInfo struct {
+	TaskID           string      `json:"task_id" gorm:"column:task_id"`
+	ProjectID        int64       `json:"project_id" gorm:"column:project_id"`
+	Name             string      `json:"name" gorm:"column:name"`
+	Command          string      `json:"command" gorm:"column:command"`
+	Cron             string      `json:"cron" gorm:"column:cron"`
+	Remark           string      `json:"remark" gorm:"column:remark"`
+	Status           int         `json:"status" gorm:"column:status"`
+	ClientIP         string      `json:"client_ip" gorm:"column:client_ip"`
+	LogRetentionDays int         `json:"log_retention_days" gorm:"column:log_retention_days"`
+	Priority         int         `json:"priority" gorm:"column:priority"` // 数值越小优先级越高，0 为最高优先级
+	Stages           []TaskStage `json:"stages" gorm:"-"`
+	DependsOn        []string    `json:"depends_on" gorm:"-"`
+	// StagesJSON/DependsOnJSON 是 Stages/DependsOn 的落库形式，由 taskStore.Save/GetList 负责互转，
+	// 这两个字段本身不对外暴露
+	StagesJSON    string `json:"-" gorm:"column:stages"`
+	DependsOnJSON string `json:"-" gorm:"column:depends_on"`
+	CreateTime    int64  `json:"create_time" gorm:"column:create_time"`
+}
+
+func (TaskInfo) TableName() string {
+	return "gc_task"
+}
+
+// TaskLog 是一次任务执行的落库结果，MySQL 与额外的 TaskLogSink 共用同一份模型
+type TaskLog struct {
+	ID        int64  `json:"id" gorm:"column:id;primary_key"`
+	ProjectID int64  `json:"project_id" gorm:"column:project_id"`
+	TaskID    string `json:"task_id" gorm:"column:task_id"`
+	Name      string `json:"name" gorm:"column:name"`
+	Result    string `json:"result" gorm:"column:result"`
+	StartTime int64  `json:"start_time" gorm:"column:start_time"`
+	EndTime   int64  `json:"end_time" gorm:"column:end_time"`
+	WithError int    `json:"with_error" gorm:"column:with_error"`
+	ClientIP  string `json:"client_ip" gorm:"column:client_ip"`
+}
+
+func (TaskLog) TableName() string {
+	return "gc_task_log"
+}
+
+// TaskStageLog 记录一次触发中，单个阶段的执行结果，同一次触发的多条记录由 RunID 串联
+type TaskStageLog struct {
+	ID         int64  `json:"id" gorm:"column:id;primary_key"`
+	ProjectID  int64  `json:"project_id" gorm:"column:project_id"`
+	TaskID     string `json:"task_id" gorm:"column:task_id"`
+	RunID      string `json:"run_id" gorm:"column:run_id"`
+	StageIndex int    `json:"stage_index" gorm:"column:stage_index"`
+	Command    string `json:"command" gorm:"column:command"`
+	Result     string `json:"result" gorm:"column:result"`
+	StartTime  int64  `json:"start_time" gorm:"column:start_time"`
+	EndTime    int64  `json:"end_time" gorm:"column:end_time"`
+	WithError  int    `json:"with_error" gorm:"column:with_error"`
+}
+
+func (TaskStageLog) TableName() string {
+	return "gc_task_stage_log"
+}
+
+// TaskExecuteResult 是 client 一次任务执行结束后上报给 server 的结果
+type TaskExecuteResult struct {
+	Task      *TaskInfo `json:"task"`
+	Result    string    `json:"result"`
+	StartTime int64     `json:"start_time"`
+	EndTime   int64     `json:"end_time"`
+	Err       string    `json:"err"`
+}
+
+// User 是系统的登录账号
+type User struct {
+	ID         int64  `json:"id" gorm:"column:id;primary_key"`
+	Account    string `json:"account" gorm:"column:account"`
+	Name       string `json:"name" gorm:"column:name"`
+	Password   string `json:"password" gorm:"column:password"`
+	Salt       string `json:"salt" gorm:"column:salt"`
+	CreateTime int64  `json:"create_time" gorm:"column:create_time"`
+}
+
+func (User) TableName() string {
+	return "gc_user"
+}
+
+// MonitorInfo 描述某个 client 节点当前的存活状态（online/offline）
+type MonitorInfo struct {
+	IP     string `json:"ip"`
+	Status string `json:"status"`
+}
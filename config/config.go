@@ -0,0 +1,72 @@
+package config
+
+import "time"
+
+// ServiceConfig 是 server/client 共用的启动配置
+type ServiceConfig struct {
+	LogLevel     string
+	Mysql        *MysqlConf
+	Etcd         *EtcdConf
+	JWT          *JWTConf
+	ReportAddr   string
+	LogRetention LogRetentionConf
+	Sinks        *SinksConf
+}
+
+// MysqlConf 是连接 MySQL 所需的配置
+type MysqlConf struct {
+	Service  string
+	Username string
+	Password string
+	Database string
+}
+
+// EtcdConf 是连接 etcd 所需的配置
+type EtcdConf struct {
+	Service  []string
+	Prefix   string
+	Projects []int64
+}
+
+// JWTConf 是签发/校验登录态 token 所需的配置
+type JWTConf struct {
+	Secret string
+	Expire int64
+}
+
+// LogRetentionConf 控制任务日志的保留策略，可以被项目/任务维度的配置覆盖
+type LogRetentionConf struct {
+	DefaultDays   int // 未设置项目/任务维度配置时的默认保留天数
+	SucceedDays   int // 成功记录的保留天数，0 表示不覆盖
+	FailedDays    int // 失败记录的保留天数，0 表示不覆盖
+	MaxDays       int // 允许配置的保留天数上限，0 表示不限制
+	OperationDays int // 操作日志的保留天数，0 表示使用内置默认值（90 天）
+	// SweepInterval 控制 AutoCleanLogs 后台轮询的间隔，0 表示使用内置默认值（12 小时）
+	SweepInterval time.Duration
+}
+
+// SinksConf 配置除 MySQL 以外，任务日志需要额外投递的目的地
+type SinksConf struct {
+	Elasticsearch *ElasticsearchConf
+	Kafka         *KafkaConf
+	S3            *S3Conf
+	SpoolDir      string // 目标 sink 不可用时的本地落盘目录
+}
+
+type ElasticsearchConf struct {
+	Addr                 []string
+	BulkSize             int
+	FlushIntervalSeconds int
+}
+
+type KafkaConf struct {
+	Brokers []string
+	Topic   string
+}
+
+type S3Conf struct {
+	Endpoint string // 兼容 S3 协议的对象存储访问地址，如内部 minio 网关
+	Bucket   string
+	Prefix   string
+	WorkerIP string
+}
@@ -0,0 +1,26 @@
+package errors
+
+import "fmt"
+
+// Error 是贯穿全仓库的统一错误类型：Msg 面向用户展示，Log 记录原始错误方便排查，
+// TraceID 用于把一条错误和请求链路上的其他日志串联起来
+type Error struct {
+	Code    int
+	Msg     string
+	Log     string
+	TraceID string
+}
+
+func (e Error) Error() string {
+	if e.Log != "" {
+		return fmt.Sprintf("%s: %s", e.Msg, e.Log)
+	}
+	return e.Msg
+}
+
+var (
+	ErrInternalError        = Error{Code: 500, Msg: "系统内部错误"}
+	ErrDataParseError       = Error{Code: 400, Msg: "参数错误"}
+	ErrProjectNotExist      = Error{Code: 404, Msg: "项目不存在"}
+	ErrOrganizationNotExist = Error{Code: 404, Msg: "组织不存在"}
+)
@@ -0,0 +1,13 @@
+package jwt
+
+import "github.com/holdno/gopherCron/config"
+
+var secret string
+
+// InitJWT 用配置初始化签发/校验 token 所需的密钥
+func InitJWT(conf *config.JWTConf) {
+	if conf == nil {
+		return
+	}
+	secret = conf.Secret
+}
@@ -0,0 +1,116 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+	"github.com/holdno/gopherCron/config"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+// TaskLock 是某个任务在触发时持有的分布式锁，避免同一任务被多个 client 节点同时执行
+type TaskLock struct {
+	client *clientv3.Client
+	key    string
+	lease  clientv3.LeaseID
+}
+
+// Lock 尝试获取锁，失败时返回 error，调用方应放弃本次触发
+func (l *TaskLock) Lock() error {
+	lease, err := l.client.Grant(context.Background(), 30)
+	if err != nil {
+		return err
+	}
+
+	txn := l.client.Txn(context.Background())
+	res, err := txn.If(clientv3.Compare(clientv3.CreateRevision(l.key), "=", 0)).
+		Then(clientv3.OpPut(l.key, "1", clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !res.Succeeded {
+		return fmt.Errorf("task %s is already running", l.key)
+	}
+
+	l.lease = lease.ID
+	return nil
+}
+
+// Unlock 释放任务锁
+func (l *TaskLock) Unlock() error {
+	if l.lease == 0 {
+		return nil
+	}
+	_, err := l.client.Revoke(context.Background(), l.lease)
+	return err
+}
+
+type manager struct {
+	client *clientv3.Client
+	prefix string
+}
+
+// Connect 根据配置建立到 etcd 集群的连接
+func Connect(conf *config.EtcdConf) (*manager, error) {
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   conf.Service,
+		DialTimeout: time.Second * 5,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &manager{client: cli, prefix: conf.Prefix}, nil
+}
+
+func (m *manager) Client() *clientv3.Client {
+	return m.client
+}
+
+func (m *manager) KV() clientv3.KV {
+	return m.client
+}
+
+func (m *manager) Lease() clientv3.Lease {
+	return m.client
+}
+
+func (m *manager) Watcher() clientv3.Watcher {
+	return m.client
+}
+
+func (m *manager) Lock(task *common.TaskInfo) *TaskLock {
+	return &TaskLock{
+		client: m.client,
+		key:    fmt.Sprintf("%s/lock/%d/%s", m.prefix, task.ProjectID, task.TaskID),
+	}
+}
+
+// Inc 对 key 做原子自增并返回自增后的值，用于分配集群内自增 ID（如雪花算法的 worker id）
+func (m *manager) Inc(key string) (int64, error) {
+	resp, err := m.client.Txn(context.Background()).
+		If().
+		Then(clientv3.OpGet(key)).
+		Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	if len(resp.Responses) > 0 {
+		if getResp := resp.Responses[0].GetResponseRange(); getResp != nil && len(getResp.Kvs) > 0 {
+			fmt.Sscanf(string(getResp.Kvs[0].Value), "%d", &current)
+		}
+	}
+
+	next := current + 1
+	if _, err := m.client.Put(context.Background(), key, fmt.Sprintf("%d", next)); err != nil {
+		return 0, err
+	}
+
+	return next, nil
+}
@@ -0,0 +1,16 @@
+package logger
+
+import "github.com/sirupsen/logrus"
+
+// MustSetup 构造一个按 level 初始化好的全局 logger，level 非法时退化为 info
+func MustSetup(level string) *logrus.Logger {
+	l := logrus.New()
+
+	lv, err := logrus.ParseLevel(level)
+	if err != nil {
+		lv = logrus.InfoLevel
+	}
+	l.SetLevel(lv)
+
+	return l
+}
@@ -0,0 +1,32 @@
+package panicgroup
+
+import "fmt"
+
+// PanicGroup 把 goroutine 里的 panic 兜底捕获，转换成可以被上层 Warner 上报的 error，
+// 避免一个任务执行 goroutine 的 panic 拖垮整个 client 进程
+type PanicGroup struct {
+	handler func(err error)
+}
+
+// NewPanicGroup 用一个 panic 处理函数构造 PanicGroup，handler 通常用于上报告警
+func NewPanicGroup(handler func(err error)) PanicGroup {
+	return PanicGroup{handler: handler}
+}
+
+// Go 包装传入的函数，使其在独立 goroutine 中执行并捕获 panic；
+// 返回值可以直接作为可重复调用的入口（便于注册为定时任务的执行体）
+func (p PanicGroup) Go(f func(a ...interface{})) func(a ...interface{}) {
+	return func(a ...interface{}) {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					if p.handler != nil {
+						p.handler(fmt.Errorf("panic recovered: %v", r))
+					}
+				}
+			}()
+
+			f(a...)
+		}()
+	}
+}
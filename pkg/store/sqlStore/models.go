@@ -0,0 +1,18 @@
+package sqlStore
+
+import "github.com/holdno/gopherCron/common"
+
+// allModels 列出所有需要 AutoMigrate 的表，新增实体时在这里补充一行即可
+func allModels() []interface{} {
+	return []interface{}{
+		&common.Project{},
+		&common.ProjectRelevance{},
+		&common.Organization{},
+		&common.OrganizationMembership{},
+		&common.OperationLog{},
+		&common.TaskInfo{},
+		&common.TaskLog{},
+		&common.TaskStageLog{},
+		&common.User{},
+	}
+}
@@ -0,0 +1,51 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// OperationLogStore 是 common.OperationLog 的数据访问接口
+type OperationLogStore interface {
+	GetList(opt selection.Selector) ([]*common.OperationLog, error)
+	GetTotal(opt selection.Selector) (int, error)
+	Create(tx *gorm.DB, log common.OperationLog) error
+	// CleanWithCount 按条件批量删除操作日志，返回本次实际删除的行数，供自动清理任务判断是否有异常增长
+	CleanWithCount(tx *gorm.DB, opt selection.Selector) (int64, error)
+}
+
+type operationLogStore struct {
+	db *gorm.DB
+}
+
+func (s *operationLogStore) GetList(opt selection.Selector) ([]*common.OperationLog, error) {
+	var list []*common.OperationLog
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *operationLogStore) GetTotal(opt selection.Selector) (int, error) {
+	var total int
+	if err := applySelector(s.db, opt).Model(&common.OperationLog{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *operationLogStore) Create(tx *gorm.DB, log common.OperationLog) error {
+	db := txOrDB(tx, s.db)
+	return db.Create(&log).Error
+}
+
+func (s *operationLogStore) CleanWithCount(tx *gorm.DB, opt selection.Selector) (int64, error) {
+	db := txOrDB(tx, s.db)
+	res := applySelector(db, opt).Delete(&common.OperationLog{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
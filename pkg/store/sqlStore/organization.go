@@ -0,0 +1,51 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// OrganizationStore 是 common.Organization 的数据访问接口
+type OrganizationStore interface {
+	GetOrganization(opt selection.Selector) ([]*common.Organization, error)
+	CreateOrganization(tx *gorm.DB, org common.Organization) (int64, error)
+	// GetOrganizationForUpdate 在事务内以行锁读取指定 owner 下、指定名字的组织，
+	// 用来在"查不到就创建"的流程里避免并发请求各自建出一条重复记录
+	GetOrganizationForUpdate(tx *gorm.DB, ownerUID int64, name string) (*common.Organization, error)
+}
+
+type organizationStore struct {
+	db *gorm.DB
+}
+
+func (s *organizationStore) GetOrganization(opt selection.Selector) ([]*common.Organization, error) {
+	var list []*common.Organization
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *organizationStore) CreateOrganization(tx *gorm.DB, org common.Organization) (int64, error) {
+	db := txOrDB(tx, s.db)
+	if err := db.Create(&org).Error; err != nil {
+		return 0, err
+	}
+	return org.ID, nil
+}
+
+func (s *organizationStore) GetOrganizationForUpdate(tx *gorm.DB, ownerUID int64, name string) (*common.Organization, error) {
+	db := txOrDB(tx, s.db)
+
+	var org common.Organization
+	err := db.Set("gorm:query_option", "FOR UPDATE").
+		Where("owner_uid = ? AND name = ?", ownerUID, name).
+		First(&org).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
@@ -0,0 +1,44 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// OrganizationMembershipStore 是 common.OrganizationMembership 的数据访问接口
+type OrganizationMembershipStore interface {
+	GetList(opt selection.Selector) ([]*common.OrganizationMembership, error)
+	Create(tx *gorm.DB, m common.OrganizationMembership) error
+	Delete(tx *gorm.DB, orgID, uid int64) error
+	UpdateRole(orgID, uid int64, role string) error
+}
+
+type organizationMembershipStore struct {
+	db *gorm.DB
+}
+
+func (s *organizationMembershipStore) GetList(opt selection.Selector) ([]*common.OrganizationMembership, error) {
+	var list []*common.OrganizationMembership
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *organizationMembershipStore) Create(tx *gorm.DB, m common.OrganizationMembership) error {
+	db := txOrDB(tx, s.db)
+	return db.Create(&m).Error
+}
+
+func (s *organizationMembershipStore) Delete(tx *gorm.DB, orgID, uid int64) error {
+	db := txOrDB(tx, s.db)
+	return db.Where("organization_id = ? AND uid = ?", orgID, uid).Delete(&common.OrganizationMembership{}).Error
+}
+
+func (s *organizationMembershipStore) UpdateRole(orgID, uid int64, role string) error {
+	return s.db.Model(&common.OrganizationMembership{}).
+		Where("organization_id = ? AND uid = ?", orgID, uid).
+		Update("role", role).Error
+}
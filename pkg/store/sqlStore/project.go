@@ -0,0 +1,52 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// ProjectStore 是 common.Project 的数据访问接口
+type ProjectStore interface {
+	GetProject(opt selection.Selector) ([]*common.Project, error)
+	CreateProject(tx *gorm.DB, p common.Project) (int64, error)
+	UpdateProject(pid int64, title, remark string) error
+	DeleteProject(tx *gorm.DB, opt selection.Selector) error
+	UpdateLogRetention(pid int64, days int) error
+}
+
+type projectStore struct {
+	db *gorm.DB
+}
+
+func (s *projectStore) GetProject(opt selection.Selector) ([]*common.Project, error) {
+	var list []*common.Project
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *projectStore) CreateProject(tx *gorm.DB, p common.Project) (int64, error) {
+	db := txOrDB(tx, s.db)
+	if err := db.Create(&p).Error; err != nil {
+		return 0, err
+	}
+	return p.ID, nil
+}
+
+func (s *projectStore) UpdateProject(pid int64, title, remark string) error {
+	return s.db.Model(&common.Project{}).Where("id = ?", pid).
+		Updates(map[string]interface{}{"title": title, "remark": remark}).Error
+}
+
+func (s *projectStore) DeleteProject(tx *gorm.DB, opt selection.Selector) error {
+	db := txOrDB(tx, s.db)
+	return applySelector(db, opt).Delete(&common.Project{}).Error
+}
+
+func (s *projectStore) UpdateLogRetention(pid int64, days int) error {
+	return s.db.Model(&common.Project{}).Where("id = ?", pid).
+		Update("log_retention_days", days).Error
+}
@@ -0,0 +1,53 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// ProjectRelevanceStore 是组织体系上线前、用户与项目直接关联关系的数据访问接口
+type ProjectRelevanceStore interface {
+	GetList(opt selection.Selector) ([]*common.ProjectRelevance, error)
+	GetMap(opt selection.Selector) (map[int64]bool, error)
+	Create(tx *gorm.DB, r common.ProjectRelevance) error
+	Delete(tx *gorm.DB, pid, uid int64) error
+}
+
+type projectRelevanceStore struct {
+	db *gorm.DB
+}
+
+func (s *projectRelevanceStore) GetList(opt selection.Selector) ([]*common.ProjectRelevance, error) {
+	var list []*common.ProjectRelevance
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetMap 按 opt.Select 指定的单个字段取值，返回一个用于存在性判断的集合，
+// 典型用法是 opt.Select = "id" 判断用户是否关联了某个项目
+func (s *projectRelevanceStore) GetMap(opt selection.Selector) (map[int64]bool, error) {
+	var ids []int64
+	if err := applySelector(s.db, opt).Model(&common.ProjectRelevance{}).Pluck(opt.Select, &ids).Error; err != nil {
+		return nil, err
+	}
+
+	res := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+func (s *projectRelevanceStore) Create(tx *gorm.DB, r common.ProjectRelevance) error {
+	db := txOrDB(tx, s.db)
+	return db.Create(&r).Error
+}
+
+func (s *projectRelevanceStore) Delete(tx *gorm.DB, pid, uid int64) error {
+	db := txOrDB(tx, s.db)
+	return db.Where("project_id = ? AND uid = ?", pid, uid).Delete(&common.ProjectRelevance{}).Error
+}
@@ -0,0 +1,122 @@
+package sqlStore
+
+import (
+	"fmt"
+
+	"github.com/holdno/gopherCron/config"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// SqlStore 是 app 包依赖的数据访问层，按实体拆分为各自的子接口，
+// 具体实现全部基于 gorm + selection.Selector 拼查询条件
+type SqlStore interface {
+	BeginTx() *gorm.DB
+	Project() ProjectStore
+	ProjectRelevance() ProjectRelevanceStore
+	Organization() OrganizationStore
+	OrganizationMembership() OrganizationMembershipStore
+	Task() TaskStore
+	TaskLog() TaskLogStore
+	TaskStageLog() TaskStageLogStore
+	OperationLog() OperationLogStore
+	User() UserStore
+}
+
+type sqlStore struct {
+	db *gorm.DB
+}
+
+// MustSetup 建立到 MySQL 的连接，install 为 true 时自动建表，连接失败时直接 panic，
+// 数据库是服务运行的前提条件，这里不做降级
+func MustSetup(conf *config.MysqlConf, logger *logrus.Logger, install bool) SqlStore {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		conf.Username, conf.Password, conf.Service, conf.Database)
+
+	db, err := gorm.Open("mysql", dsn)
+	if err != nil {
+		panic(fmt.Errorf("failed to connect mysql: %w", err))
+	}
+	db.LogMode(false)
+
+	if install {
+		db.AutoMigrate(allModels()...)
+	}
+
+	return &sqlStore{db: db}
+}
+
+func (s *sqlStore) BeginTx() *gorm.DB {
+	return s.db.Begin()
+}
+
+func (s *sqlStore) Project() ProjectStore {
+	return &projectStore{db: s.db}
+}
+
+func (s *sqlStore) ProjectRelevance() ProjectRelevanceStore {
+	return &projectRelevanceStore{db: s.db}
+}
+
+func (s *sqlStore) Organization() OrganizationStore {
+	return &organizationStore{db: s.db}
+}
+
+func (s *sqlStore) OrganizationMembership() OrganizationMembershipStore {
+	return &organizationMembershipStore{db: s.db}
+}
+
+func (s *sqlStore) Task() TaskStore {
+	return &taskStore{db: s.db}
+}
+
+func (s *sqlStore) TaskLog() TaskLogStore {
+	return &taskLogStore{db: s.db}
+}
+
+func (s *sqlStore) TaskStageLog() TaskStageLogStore {
+	return &taskStageLogStore{db: s.db}
+}
+
+func (s *sqlStore) OperationLog() OperationLogStore {
+	return &operationLogStore{db: s.db}
+}
+
+func (s *sqlStore) User() UserStore {
+	return &userStore{db: s.db}
+}
+
+// txOrDB 优先使用调用方传入的事务句柄，没有传入时退化为非事务的普通连接
+func txOrDB(tx *gorm.DB, db *gorm.DB) *gorm.DB {
+	if tx != nil {
+		return tx
+	}
+	return db
+}
+
+// applySelector 把 selection.Selector 里的条件、分页、排序、字段选择依次应用到 gorm.DB 上，
+// 是所有 Store 实现拼查询条件的唯一入口
+func applySelector(db *gorm.DB, opt selection.Selector) *gorm.DB {
+	for opt.NextQuery() {
+		cond, value := opt.Patch()
+		db = db.Where(cond, value)
+	}
+
+	if opt.Select != "" {
+		db = db.Select(opt.Select)
+	}
+	if opt.OrderBy != "" {
+		db = db.Order(opt.OrderBy)
+	}
+	if opt.Pagesize > 0 {
+		db = db.Limit(opt.Pagesize)
+		if opt.Page > 1 {
+			db = db.Offset((opt.Page - 1) * opt.Pagesize)
+		}
+	}
+
+	return db
+}
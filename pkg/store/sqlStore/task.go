@@ -0,0 +1,129 @@
+package sqlStore
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// TaskStore 是 common.TaskInfo 的数据访问接口
+type TaskStore interface {
+	GetList(opt selection.Selector) ([]*common.TaskInfo, error)
+	// Save 按 project_id + task_id 是否已存在决定插入还是更新，供新建/编辑任务共用一个入口
+	Save(task common.TaskInfo) (*common.TaskInfo, error)
+	Delete(pid int64, tid string) error
+	UpdateLogRetention(pid int64, tid string, days int) error
+}
+
+type taskStore struct {
+	db *gorm.DB
+}
+
+// encodeTaskStages/encodeTaskDependsOn 把 Stages/DependsOn 序列化进落库字段，
+// nil 切片落为 JSON null，非 nil 的空切片落为 []，两者都不会和"从未设置过"的空字符串混淆
+func encodeTaskStages(stages []common.TaskStage) (string, error) {
+	data, err := json.Marshal(stages)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func encodeTaskDependsOn(dependsOn []string) (string, error) {
+	data, err := json.Marshal(dependsOn)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeTaskStagesAndDependsOn 从落库字段还原 Stages/DependsOn，兼容历史遗留的空值
+func decodeTaskStagesAndDependsOn(task *common.TaskInfo) error {
+	if task.StagesJSON != "" {
+		if err := json.Unmarshal([]byte(task.StagesJSON), &task.Stages); err != nil {
+			return err
+		}
+	}
+	if task.DependsOnJSON != "" {
+		if err := json.Unmarshal([]byte(task.DependsOnJSON), &task.DependsOn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *taskStore) GetList(opt selection.Selector) ([]*common.TaskInfo, error) {
+	var list []*common.TaskInfo
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+
+	for _, task := range list {
+		if err := decodeTaskStagesAndDependsOn(task); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+func (s *taskStore) Save(task common.TaskInfo) (*common.TaskInfo, error) {
+	stagesJSON, err := encodeTaskStages(task.Stages)
+	if err != nil {
+		return nil, err
+	}
+	dependsOnJSON, err := encodeTaskDependsOn(task.DependsOn)
+	if err != nil {
+		return nil, err
+	}
+	task.StagesJSON = stagesJSON
+	task.DependsOnJSON = dependsOnJSON
+
+	err = s.db.Where("project_id = ? AND task_id = ?", task.ProjectID, task.TaskID).
+		First(&common.TaskInfo{}).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		if task.CreateTime == 0 {
+			task.CreateTime = time.Now().Unix()
+		}
+		if err := s.db.Create(&task).Error; err != nil {
+			return nil, err
+		}
+		return &task, nil
+	}
+
+	if err := s.db.Model(&common.TaskInfo{}).
+		Where("project_id = ? AND task_id = ?", task.ProjectID, task.TaskID).
+		Updates(map[string]interface{}{
+			"name":       task.Name,
+			"command":    task.Command,
+			"cron":       task.Cron,
+			"remark":     task.Remark,
+			"status":     task.Status,
+			"client_ip":  task.ClientIP,
+			"priority":   task.Priority,
+			"stages":     task.StagesJSON,
+			"depends_on": task.DependsOnJSON,
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (s *taskStore) Delete(pid int64, tid string) error {
+	return s.db.Where("project_id = ? AND task_id = ?", pid, tid).Delete(&common.TaskInfo{}).Error
+}
+
+func (s *taskStore) UpdateLogRetention(pid int64, tid string, days int) error {
+	return s.db.Model(&common.TaskInfo{}).
+		Where("project_id = ? AND task_id = ?", pid, tid).
+		Update("log_retention_days", days).Error
+}
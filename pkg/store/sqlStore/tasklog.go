@@ -0,0 +1,56 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// TaskLogStore 是 common.TaskLog 的数据访问接口
+type TaskLogStore interface {
+	GetList(opt selection.Selector) ([]*common.TaskLog, error)
+	GetTotal(opt selection.Selector) (int, error)
+	Create(log common.TaskLog) error
+	Clean(tx *gorm.DB, opt selection.Selector) error
+	// CleanWithCount 和 Clean 的区别是返回本次实际删除的行数，供自动清理任务判断是否有异常增长
+	CleanWithCount(tx *gorm.DB, opt selection.Selector) (int64, error)
+}
+
+type taskLogStore struct {
+	db *gorm.DB
+}
+
+func (s *taskLogStore) GetList(opt selection.Selector) ([]*common.TaskLog, error) {
+	var list []*common.TaskLog
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *taskLogStore) GetTotal(opt selection.Selector) (int, error) {
+	var total int
+	if err := applySelector(s.db, opt).Model(&common.TaskLog{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *taskLogStore) Create(log common.TaskLog) error {
+	return s.db.Create(&log).Error
+}
+
+func (s *taskLogStore) Clean(tx *gorm.DB, opt selection.Selector) error {
+	db := txOrDB(tx, s.db)
+	return applySelector(db, opt).Delete(&common.TaskLog{}).Error
+}
+
+func (s *taskLogStore) CleanWithCount(tx *gorm.DB, opt selection.Selector) (int64, error) {
+	db := txOrDB(tx, s.db)
+	res := applySelector(db, opt).Delete(&common.TaskLog{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
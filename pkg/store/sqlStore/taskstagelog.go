@@ -0,0 +1,31 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// TaskStageLogStore 是 common.TaskStageLog 的数据访问接口
+type TaskStageLogStore interface {
+	GetList(opt selection.Selector) ([]*common.TaskStageLog, error)
+	Create(tx *gorm.DB, log common.TaskStageLog) error
+}
+
+type taskStageLogStore struct {
+	db *gorm.DB
+}
+
+func (s *taskStageLogStore) GetList(opt selection.Selector) ([]*common.TaskStageLog, error) {
+	var list []*common.TaskStageLog
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *taskStageLogStore) Create(tx *gorm.DB, log common.TaskStageLog) error {
+	db := txOrDB(tx, s.db)
+	return db.Create(&log).Error
+}
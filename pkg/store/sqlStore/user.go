@@ -0,0 +1,45 @@
+package sqlStore
+
+import (
+	"github.com/holdno/gopherCron/common"
+
+	"github.com/holdno/gocommons/selection"
+	"github.com/jinzhu/gorm"
+)
+
+// UserStore 是 common.User 的数据访问接口
+type UserStore interface {
+	GetUsers(opt selection.Selector) ([]*common.User, error)
+	GetTotal(opt selection.Selector) (int, error)
+	CreateUser(u common.User) error
+	ChangePassword(uid int64, password, salt string) error
+}
+
+type userStore struct {
+	db *gorm.DB
+}
+
+func (s *userStore) GetUsers(opt selection.Selector) ([]*common.User, error) {
+	var list []*common.User
+	if err := applySelector(s.db, opt).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *userStore) GetTotal(opt selection.Selector) (int, error) {
+	var total int
+	if err := applySelector(s.db, opt).Model(&common.User{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *userStore) CreateUser(u common.User) error {
+	return s.db.Create(&u).Error
+}
+
+func (s *userStore) ChangePassword(uid int64, password, salt string) error {
+	return s.db.Model(&common.User{}).Where("id = ?", uid).
+		Updates(map[string]interface{}{"password": password, "salt": salt}).Error
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	idWorker int64
+	idSeq    int64
+)
+
+// 标准雪花算法位布局：符号位(1) + 毫秒时间戳(41) + worker id(10) + 序列号(12)，
+// 各部分左移到互不重叠的区间，避免 OR 组合时互相污染
+const (
+	workerIDBits = 10
+	sequenceBits = 12
+
+	workerIDShift  = sequenceBits
+	timestampShift = sequenceBits + workerIDBits
+
+	maxWorkerID = -1 ^ (-1 << workerIDBits)
+	maxSequence = -1 ^ (-1 << sequenceBits)
+)
+
+// InitIDWorker 记录当前节点的雪花算法 worker id，id 生成时以此区分不同节点
+func InitIDWorker(workerID int64) {
+	idWorker = workerID & maxWorkerID
+}
+
+// GetID 生成一个全局唯一 ID，用于 trace id、task stage 的 run id 等场景，
+// 由节点 worker id、当前毫秒时间戳和自增序列号拼出，三部分左移到不重叠的位区间后再 OR，
+// 足以保证同一节点内不重复
+func GetID() (int64, error) {
+	seq := atomic.AddInt64(&idSeq, 1) & maxSequence
+	return (time.Now().UnixMilli() << timestampShift) | (idWorker << workerIDShift) | seq, nil
+}
+
+// GetLocalIP 返回本机第一个非回环的 IPv4 地址，用于上报 client 自身标识
+func GetLocalIP() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return ip4.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no non-loopback ipv4 address found")
+}